@@ -21,6 +21,7 @@ import (
 	"github.com/kk/elk-helper/backend/internal/repository/database"
 	"github.com/kk/elk-helper/backend/internal/service/alert"
 	es_config "github.com/kk/elk-helper/backend/internal/service/esconfig"
+	lark_config "github.com/kk/elk-helper/backend/internal/service/larkconfig"
 	"github.com/kk/elk-helper/backend/internal/service/query"
 	"github.com/kk/elk-helper/backend/internal/service/rule"
 	system_config "github.com/kk/elk-helper/backend/internal/service/systemconfig"
@@ -57,6 +58,12 @@ func main() {
 	}
 	defer database.Close()
 
+	// Move Lark configs and rule webhooks to lark notification channels
+	if err := lark_config.NewService().MigrateToChannels(); err != nil {
+		slog.Error("Failed to migrate Lark configs to channels", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize services
 	queryService, err := query.NewService()
 	if err != nil {
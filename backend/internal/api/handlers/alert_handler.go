@@ -6,21 +6,28 @@
 package handlers
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kk/elk-helper/backend/internal/config"
+	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/service/alert"
+	"github.com/kk/elk-helper/backend/internal/service/rule"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
 )
 
 type AlertHandler struct {
-	service *alert.Service
+	service     *alert.Service
+	ruleService *rule.Service
 }
 
 func NewAlertHandler() *AlertHandler {
 	return &AlertHandler{
-		service: alert.NewService(),
+		service:     alert.NewService(),
+		ruleService: rule.NewService(),
 	}
 }
 
@@ -29,6 +36,7 @@ func NewAlertHandler() *AlertHandler {
 // @Tags alerts
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
+// @Param state query string false "Lifecycle state: firing, acknowledged, resolved or open"
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/alerts [get]
@@ -43,7 +51,15 @@ func (h *AlertHandler) GetAlerts(c *gin.Context) {
 		pageSize = 20
 	}
 
-	alerts, total, err := h.service.GetAll(page, pageSize)
+	state := c.Query("state")
+	switch models.AlertState(state) {
+	case "", "open", models.AlertStateFiring, models.AlertStateAcknowledged, models.AlertStateResolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+		return
+	}
+
+	alerts, total, err := h.service.GetAll(page, pageSize, state)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -82,6 +98,99 @@ func (h *AlertHandler) GetAlert(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": alert})
 }
 
+// AcknowledgeAlert acknowledges a firing alert; further matches stop notifying until it resolves
+// @Summary Acknowledge an alert
+// @Tags alerts
+// @Param id path int true "Alert ID"
+// @Produce json
+// @Success 200 {object} models.Alert
+// @Router /api/v1/alerts/{id}/acknowledge [post]
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert ID"})
+		return
+	}
+
+	ok, err := h.service.Acknowledge(uint(id), c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "only firing alerts can be acknowledged"})
+		return
+	}
+
+	alert, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": alert})
+}
+
+// ResolveAlert resolves an open alert manually and sends the resolved notification
+// @Summary Resolve an alert
+// @Tags alerts
+// @Param id path int true "Alert ID"
+// @Produce json
+// @Success 200 {object} models.Alert
+// @Router /api/v1/alerts/{id}/resolve [post]
+func (h *AlertHandler) ResolveAlert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert ID"})
+		return
+	}
+
+	ok, err := h.service.Resolve(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "alert is already resolved"})
+		return
+	}
+
+	alert, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Notify the rule's channels in the background, like the executor does;
+	// alerts whose notification was silenced resolve quietly
+	if alert.Status != models.AlertStatusSilenced {
+		go h.sendResolved(alert)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alert})
+}
+
+// sendResolved sends the resolved notification of a manually resolved alert
+func (h *AlertHandler) sendResolved(alert *models.Alert) {
+	ruleModel, err := h.ruleService.GetByID(alert.RuleID)
+	if err != nil {
+		slog.Warn("Failed to load rule for resolved notification", "alert_id", alert.ID, "rule_id", alert.RuleID, "error", err)
+		return
+	}
+	targets, err := notifier.ForRule(ruleModel)
+	if err != nil {
+		slog.Warn("No notification channel for resolved notification", "alert_id", alert.ID, "rule_id", alert.RuleID, "error", err)
+		return
+	}
+
+	retryTimes := 3
+	if config.AppConfig != nil && config.AppConfig.Worker.RetryTimes > 0 {
+		retryTimes = config.AppConfig.Worker.RetryTimes
+	}
+	if err := notifier.SendResolved(targets, notifier.NewResolvedMessage(ruleModel, alert, time.Now()), retryTimes); err != nil {
+		slog.Error("Resolved notification failed", "alert_id", alert.ID, "rule_id", alert.RuleID, "error", err)
+	}
+}
+
 // DeleteAlert deletes an alert
 // @Summary Delete an alert
 // @Tags alerts
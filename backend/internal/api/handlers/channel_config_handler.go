@@ -0,0 +1,257 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kk/elk-helper/backend/internal/models"
+	channel_config "github.com/kk/elk-helper/backend/internal/service/channelconfig"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// channelSecretKeys are settings keys never returned by the API (same as ESConfig.Password)
+var channelSecretKeys = []string{"password", "secret", "headers", "signature_secret", "bearer_token", "routing_key", "api_key"}
+
+type ChannelConfigHandler struct {
+	service *channel_config.Service
+}
+
+func NewChannelConfigHandler() *ChannelConfigHandler {
+	return &ChannelConfigHandler{
+		service: channel_config.NewService(),
+	}
+}
+
+// GetChannelConfigs returns all notification channel configurations
+// @Summary Get all notification channel configurations
+// @Tags channel-configs
+// @Produce json
+// @Success 200 {array} models.ChannelConfig
+// @Router /api/v1/channel-configs [get]
+func (h *ChannelConfigHandler) GetChannelConfigs(c *gin.Context) {
+	configs, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range configs {
+		redactChannelSecrets(&configs[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": configs})
+}
+
+// GetChannelConfig returns a channel config by ID
+// @Summary Get notification channel config by ID
+// @Tags channel-configs
+// @Param id path int true "Config ID"
+// @Produce json
+// @Success 200 {object} models.ChannelConfig
+// @Router /api/v1/channel-configs/{id} [get]
+func (h *ChannelConfigHandler) GetChannelConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid config ID"})
+		return
+	}
+
+	config, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	redactChannelSecrets(config)
+
+	c.JSON(http.StatusOK, gin.H{"data": config})
+}
+
+// CreateChannelConfig creates a new notification channel configuration
+// @Summary Create a new notification channel configuration
+// @Tags channel-configs
+// @Accept json
+// @Produce json
+// @Param config body models.ChannelConfig true "Channel Config data"
+// @Success 201 {object} models.ChannelConfig
+// @Router /api/v1/channel-configs [post]
+func (h *ChannelConfigHandler) CreateChannelConfig(c *gin.Context) {
+	var config models.ChannelConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate type and settings by building the notifier
+	if _, err := notifier.New(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Create(&config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	redactChannelSecrets(&config)
+
+	c.JSON(http.StatusCreated, gin.H{"data": config})
+}
+
+// UpdateChannelConfig updates an existing notification channel configuration
+// @Summary Update a notification channel configuration
+// @Tags channel-configs
+// @Accept json
+// @Produce json
+// @Param id path int true "Config ID"
+// @Param config body models.ChannelConfig true "Channel Config data"
+// @Success 200 {object} models.ChannelConfig
+// @Router /api/v1/channel-configs/{id} [put]
+func (h *ChannelConfigHandler) UpdateChannelConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid config ID"})
+		return
+	}
+
+	var config models.ChannelConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get existing config to preserve secrets if not provided
+	existingConfig, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "config not found"})
+		return
+	}
+	preserveChannelSecrets(&config, existingConfig)
+
+	if _, err := notifier.New(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Update(uint(id), &config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedConfig, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	redactChannelSecrets(updatedConfig)
+
+	c.JSON(http.StatusOK, gin.H{"data": updatedConfig})
+}
+
+// DeleteChannelConfig deletes a notification channel configuration
+// @Summary Delete a notification channel configuration
+// @Tags channel-configs
+// @Param id path int true "Config ID"
+// @Success 204
+// @Router /api/v1/channel-configs/{id} [delete]
+func (h *ChannelConfigHandler) DeleteChannelConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid config ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestChannelConfig sends a test message through the channel
+// @Summary Test notification channel configuration
+// @Tags channel-configs
+// @Param id path int true "Config ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/channel-configs/{id}/test [post]
+func (h *ChannelConfigHandler) TestChannelConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid config ID"})
+		return
+	}
+
+	config, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	n, err := notifier.New(config)
+	if err == nil {
+		err = n.SendTest()
+	}
+	if err != nil {
+		h.service.UpdateTestResult(uint(id), "failed", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	h.service.UpdateTestResult(uint(id), "success", "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "通知渠道测试成功",
+	})
+}
+
+// redactChannelSecrets removes secret settings from API responses. The settings map
+// is replaced by a copy so configs shared with the executor keep their secrets.
+func redactChannelSecrets(config *models.ChannelConfig) {
+	if config.Settings == nil {
+		return
+	}
+	redacted := make(models.ChannelSettings, len(config.Settings))
+	for key, value := range config.Settings {
+		redacted[key] = value
+	}
+	for _, key := range channelSecretKeys {
+		delete(redacted, key)
+	}
+	config.Settings = redacted
+}
+
+// redactRuleSecrets removes the secret settings of a rule's preloaded channels from API responses
+func redactRuleSecrets(rule *models.Rule) {
+	for i := range rule.Channels {
+		redactChannelSecrets(&rule.Channels[i])
+	}
+}
+
+// preserveChannelSecrets keeps existing secret settings when the request omits them
+func preserveChannelSecrets(config, existing *models.ChannelConfig) {
+	if existing == nil || config.Type != existing.Type {
+		return
+	}
+	for _, key := range channelSecretKeys {
+		if _, provided := config.Settings[key]; provided {
+			continue
+		}
+		if value, ok := existing.Settings[key]; ok {
+			if config.Settings == nil {
+				config.Settings = models.ChannelSettings{}
+			}
+			config.Settings[key] = value
+		}
+	}
+}
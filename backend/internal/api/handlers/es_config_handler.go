@@ -285,6 +285,8 @@ func (h *ESConfigHandler) TestESConfig(c *gin.Context) {
 		})
 		return
 	}
+	// The per-test client is not shared through the registry
+	defer queryService.Close()
 
 	// Test connection with ping
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -6,18 +6,22 @@
 package handlers
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/service/larkconfig"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
 )
 
+// larkSignSecretRequest carries the write-only sign secret (never returned, like ESConfig.Password).
+// A nil value means the field was not provided and the existing secret is kept.
+type larkSignSecretRequest struct {
+	SignSecret *string `json:"sign_secret"`
+}
+
 type LarkConfigHandler struct {
 	service *lark_config.Service
 }
@@ -77,10 +81,18 @@ func (h *LarkConfigHandler) GetLarkConfig(c *gin.Context) {
 // @Router /api/v1/lark-configs [post]
 func (h *LarkConfigHandler) CreateLarkConfig(c *gin.Context) {
 	var config models.LarkConfig
-	if err := c.ShouldBindJSON(&config); err != nil {
+	if err := c.ShouldBindBodyWith(&config, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var secretReq larkSignSecretRequest
+	if err := c.ShouldBindBodyWith(&secretReq, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if secretReq.SignSecret != nil {
+		config.SignSecret = *secretReq.SignSecret
+	}
 
 	if err := h.service.Create(&config); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -107,7 +119,12 @@ func (h *LarkConfigHandler) UpdateLarkConfig(c *gin.Context) {
 	}
 
 	var config models.LarkConfig
-	if err := c.ShouldBindJSON(&config); err != nil {
+	if err := c.ShouldBindBodyWith(&config, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var secretReq larkSignSecretRequest
+	if err := c.ShouldBindBodyWith(&secretReq, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,6 +134,14 @@ func (h *LarkConfigHandler) UpdateLarkConfig(c *gin.Context) {
 		return
 	}
 
+	// sign_secret not provided: keep existing; empty string: disable signing
+	if secretReq.SignSecret != nil {
+		if err := h.service.UpdateSignSecret(uint(id), *secretReq.SignSecret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	updatedConfig, err := h.service.GetByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -166,16 +191,8 @@ func (h *LarkConfigHandler) TestLarkConfig(c *gin.Context) {
 		return
 	}
 
-	// Test webhook by sending a test message
-	testMessage := map[string]interface{}{
-		"msg_type": "text",
-		"content": map[string]interface{}{
-			"text": "测试消息：ELK Helper 连接测试",
-		},
-	}
-
-	body, err := json.Marshal(testMessage)
-	if err != nil {
+	// Test through the notifier so the signed path is exercised as well
+	if err := notifier.NewLarkClient(config.WebhookURL, config.SignSecret).SendTest(); err != nil {
 		h.service.UpdateTestResult(uint(id), "failed", err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -184,62 +201,10 @@ func (h *LarkConfigHandler) TestLarkConfig(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", config.WebhookURL, bytes.NewReader(body))
-	if err != nil {
-		h.service.UpdateTestResult(uint(id), "failed", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		h.service.UpdateTestResult(uint(id), "failed", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
-	defer resp.Body.Close()
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		h.service.UpdateTestResult(uint(id), "failed", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
-
-	if resp.StatusCode == http.StatusOK {
-		if code, ok := result["code"].(float64); ok && code == 0 {
-			h.service.UpdateTestResult(uint(id), "success", "")
-			c.JSON(http.StatusOK, gin.H{
-				"success": true,
-				"message": "Webhook 测试成功",
-			})
-			return
-		}
-	}
-
-	errMsg := "Lark API 返回错误"
-	if msg, ok := result["msg"].(string); ok {
-		errMsg = msg
-	}
-	h.service.UpdateTestResult(uint(id), "failed", errMsg)
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error":   errMsg,
+	h.service.UpdateTestResult(uint(id), "success", "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook 测试成功",
 	})
 }
 
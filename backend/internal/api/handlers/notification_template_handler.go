@@ -0,0 +1,274 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kk/elk-helper/backend/internal/models"
+	notification_template "github.com/kk/elk-helper/backend/internal/service/notificationtemplate"
+	"github.com/kk/elk-helper/backend/internal/service/rule"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// previewSampleLogs are rendered when a preview request carries no logs
+var previewSampleLogs = []map[string]interface{}{
+	{
+		"@timestamp":    "2025-01-01T08:00:00.000Z",
+		"response_code": 502,
+		"request":       "/api/v1/orders?page=1",
+		"domain":        "shop.example.com",
+		"cf_ray":        "8f1a2b3c4d5e6f70-HKG",
+		"message":       "upstream prematurely closed connection while reading response header",
+	},
+	{
+		"@timestamp":    "2025-01-01T08:00:05.000Z",
+		"response_code": 504,
+		"request":       "/api/v1/payments",
+		"domain":        "shop.example.com",
+		"cf_ray":        "8f1a2b3c4d5e6f71-HKG",
+		"message":       "upstream timed out (110: Connection timed out)",
+	},
+}
+
+type NotificationTemplateHandler struct {
+	service     *notification_template.Service
+	ruleService *rule.Service
+}
+
+func NewNotificationTemplateHandler() *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		service:     notification_template.NewService(),
+		ruleService: rule.NewService(),
+	}
+}
+
+// previewTemplateRequest renders a saved template (template_id) or an unsaved one (template)
+type previewTemplateRequest struct {
+	TemplateID *uint                        `json:"template_id"`
+	Template   *models.NotificationTemplate `json:"template"`
+	RuleID     *uint                        `json:"rule_id"` // 使用该规则的名称、索引和展示字段
+	Logs       []map[string]interface{}     `json:"logs"`    // 示例日志，为空使用内置示例
+}
+
+// GetNotificationTemplates returns all notification templates
+// @Summary Get all notification templates
+// @Tags notification-templates
+// @Produce json
+// @Success 200 {array} models.NotificationTemplate
+// @Router /api/v1/notification-templates [get]
+func (h *NotificationTemplateHandler) GetNotificationTemplates(c *gin.Context) {
+	templates, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": templates})
+}
+
+// GetDefaultNotificationTemplate returns the template equivalent to the built-in layout
+// @Summary Get the built-in notification template
+// @Tags notification-templates
+// @Produce json
+// @Success 200 {object} models.NotificationTemplate
+// @Router /api/v1/notification-templates/default [get]
+func (h *NotificationTemplateHandler) GetDefaultNotificationTemplate(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": notifier.DefaultTemplate})
+}
+
+// GetNotificationTemplate returns a notification template by ID
+// @Summary Get notification template by ID
+// @Tags notification-templates
+// @Param id path int true "Template ID"
+// @Produce json
+// @Success 200 {object} models.NotificationTemplate
+// @Router /api/v1/notification-templates/{id} [get]
+func (h *NotificationTemplateHandler) GetNotificationTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	tmpl, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tmpl})
+}
+
+// CreateNotificationTemplate creates a new notification template
+// @Summary Create a new notification template
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param template body models.NotificationTemplate true "Notification template data"
+// @Success 201 {object} models.NotificationTemplate
+// @Router /api/v1/notification-templates [post]
+func (h *NotificationTemplateHandler) CreateNotificationTemplate(c *gin.Context) {
+	var tmpl models.NotificationTemplate
+	if err := c.ShouldBindJSON(&tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := notifier.ValidateTemplate(&tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Create(&tmpl); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": tmpl})
+}
+
+// UpdateNotificationTemplate updates an existing notification template
+// @Summary Update a notification template
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body models.NotificationTemplate true "Notification template data"
+// @Success 200 {object} models.NotificationTemplate
+// @Router /api/v1/notification-templates/{id} [put]
+func (h *NotificationTemplateHandler) UpdateNotificationTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	var tmpl models.NotificationTemplate
+	if err := c.ShouldBindJSON(&tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.service.GetByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+
+	if err := notifier.ValidateTemplate(&tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Update(uint(id), &tmpl); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// DeleteNotificationTemplate deletes a notification template
+// @Summary Delete a notification template
+// @Tags notification-templates
+// @Param id path int true "Template ID"
+// @Success 204
+// @Router /api/v1/notification-templates/{id} [delete]
+func (h *NotificationTemplateHandler) DeleteNotificationTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewNotificationTemplate renders a template against sample logs without sending it
+// @Summary Preview a notification template
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param request body previewTemplateRequest true "Template and sample logs"
+// @Success 200 {object} notifier.RenderedMessage
+// @Router /api/v1/notification-templates/preview [post]
+func (h *NotificationTemplateHandler) PreviewNotificationTemplate(c *gin.Context) {
+	var req previewTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tmpl := &notifier.DefaultTemplate
+	switch {
+	case req.Template != nil:
+		tmpl = req.Template
+		if tmpl.Name == "" {
+			tmpl.Name = "preview"
+		}
+		if err := notifier.ValidateTemplate(tmpl); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case req.TemplateID != nil:
+		saved, err := h.service.GetByID(*req.TemplateID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		tmpl = saved
+	}
+
+	logs := req.Logs
+	if len(logs) == 0 {
+		logs = previewSampleLogs
+	}
+
+	now := time.Now()
+	msg := &notifier.AlertMessage{
+		RuleName:  "示例规则",
+		IndexName: "nginx-access-*",
+		Logs:      logs,
+		LogCount:  len(logs),
+		FromTime:  now.Add(-time.Minute),
+		ToTime:    now,
+	}
+	if req.RuleID != nil {
+		ruleModel, err := h.ruleService.GetByID(*req.RuleID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		msg.Rule = ruleModel
+		msg.RuleName = ruleModel.Name
+		msg.IndexName = ruleModel.IndexPattern
+		if ruleModel.Interval > 0 {
+			msg.FromTime = now.Add(-time.Duration(ruleModel.Interval) * time.Second)
+		}
+	}
+
+	rendered, err := notifier.RenderTemplate(tmpl, notifier.NewTemplateData(msg))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rendered})
+}
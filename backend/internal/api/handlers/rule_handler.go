@@ -6,6 +6,8 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -14,27 +16,36 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/kk/elk-helper/backend/internal/models"
+	channel_config "github.com/kk/elk-helper/backend/internal/service/channelconfig"
 	es_config "github.com/kk/elk-helper/backend/internal/service/esconfig"
 	lark_config "github.com/kk/elk-helper/backend/internal/service/larkconfig"
+	notification_template "github.com/kk/elk-helper/backend/internal/service/notificationtemplate"
 	"github.com/kk/elk-helper/backend/internal/service/query"
 	"github.com/kk/elk-helper/backend/internal/service/rule"
+	"github.com/kk/elk-helper/backend/internal/service/schedule"
 	"github.com/kk/elk-helper/backend/internal/worker/scheduler"
 )
 
 type RuleHandler struct {
-	service           *rule.Service
-	queryService      *query.Service
-	esConfigService   *es_config.Service
-	larkConfigService *lark_config.Service
+	service              *rule.Service
+	queryService         *query.Service
+	esConfigService      *es_config.Service
+	larkConfigService    *lark_config.Service
+	channelConfigService *channel_config.Service
+	templateService      *notification_template.Service
+	scheduleService      *schedule.Service
 }
 
 func NewRuleHandler() *RuleHandler {
 	queryService, _ := query.NewService()
 	return &RuleHandler{
-		service:           rule.NewService(),
-		queryService:      queryService,
-		esConfigService:   es_config.NewService(),
-		larkConfigService: lark_config.NewService(),
+		service:              rule.NewService(),
+		queryService:         queryService,
+		esConfigService:      es_config.NewService(),
+		larkConfigService:    lark_config.NewService(),
+		channelConfigService: channel_config.NewService(),
+		templateService:      notification_template.NewService(),
+		scheduleService:      schedule.NewService(),
 	}
 }
 
@@ -57,6 +68,9 @@ func (h *RuleHandler) GetRules(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		for i := range rules {
+			redactRuleSecrets(&rules[i])
+		}
 		c.JSON(http.StatusOK, gin.H{"data": rules})
 		return
 	}
@@ -75,6 +89,9 @@ func (h *RuleHandler) GetRules(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	for i := range rules {
+		redactRuleSecrets(&rules[i])
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": rules,
@@ -106,6 +123,7 @@ func (h *RuleHandler) GetRule(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	redactRuleSecrets(rule)
 	c.JSON(http.StatusOK, gin.H{"data": rule})
 }
 
@@ -124,6 +142,31 @@ func (h *RuleHandler) CreateRule(c *gin.Context) {
 		return
 	}
 
+	if err := rule.ValidateDisplay(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateGrouping(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateType(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateTimeWindow(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validateRuleQuery(c.Request.Context(), &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.service.Create(&rule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -136,6 +179,7 @@ func (h *RuleHandler) CreateRule(c *gin.Context) {
 		}
 	}
 
+	redactRuleSecrets(&rule)
 	c.JSON(http.StatusCreated, gin.H{"data": rule})
 }
 
@@ -161,6 +205,31 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 		return
 	}
 
+	if err := rule.ValidateDisplay(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateGrouping(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateType(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rule.ValidateTimeWindow(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validateRuleQuery(c.Request.Context(), &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.service.Update(uint(id), &rule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -179,6 +248,7 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 		}
 	}
 
+	redactRuleSecrets(updatedRule)
 	c.JSON(http.StatusOK, gin.H{"data": updatedRule})
 }
 
@@ -234,9 +304,38 @@ func (h *RuleHandler) ToggleRuleEnabled(c *gin.Context) {
 		}
 	}
 
+	redactRuleSecrets(rule)
 	c.JSON(http.StatusOK, gin.H{"data": rule})
 }
 
+// validateRuleQuery checks the query of the rule's query mode; dsl and query_string
+// queries are validated with the ES _validate/query API of the rule's data source
+func (h *RuleHandler) validateRuleQuery(ctx context.Context, rule *models.Rule) error {
+	if err := rule.ValidateQueryMode(); err != nil {
+		return err
+	}
+	if rule.EffectiveQueryMode() == models.QueryModeConditions {
+		return nil
+	}
+
+	queryService := h.queryService
+	if rule.ESConfigID != nil {
+		esConfig, err := h.esConfigService.GetByID(*rule.ESConfigID)
+		if err != nil {
+			return fmt.Errorf("ES config not found: %w", err)
+		}
+		queryService, err = query.ServiceForConfig(esConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create query service: %w", err)
+		}
+	}
+	if queryService == nil {
+		return fmt.Errorf("an Elasticsearch data source is required to validate the query")
+	}
+
+	return queryService.ValidateQuery(ctx, rule)
+}
+
 // TestRule tests a rule's query without saving
 // @Summary Test rule query
 // @Tags rules
@@ -252,6 +351,11 @@ func (h *RuleHandler) TestRule(c *gin.Context) {
 		return
 	}
 
+	if err := rule.ValidateQueryMode(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "success": false})
+		return
+	}
+
 	// Get query service based on rule's ES config
 	var queryService *query.Service
 	var err error
@@ -301,7 +405,7 @@ func (h *RuleHandler) TestRule(c *gin.Context) {
 			return
 		}
 
-		queryService, err = query.NewServiceFromConfig(esConfig)
+		queryService, err = query.ServiceForConfig(esConfig)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   err.Error(),
@@ -325,7 +429,7 @@ func (h *RuleHandler) TestRule(c *gin.Context) {
 	toTime := time.Now()
 	fromTime := toTime.Add(-10 * time.Minute)
 
-	logs, err := queryService.QueryLogs(c.Request.Context(), &rule, fromTime, toTime, 100)
+	result, err := queryService.QueryLogs(c.Request.Context(), &rule, fromTime, toTime, 100)
 	if err != nil {
 		errorMsg := err.Error()
 		// Provide more helpful error message for 401 errors
@@ -346,8 +450,11 @@ func (h *RuleHandler) TestRule(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"count": len(logs),
-			"logs":  logs,
+			"count":     len(result.Logs),
+			"logs":      result.Logs,
+			"total":     result.Total,
+			"truncated": result.Truncated,
+			"warning":   result.Warning(),
 			"time_range": gin.H{
 				"from": fromTime.Format(time.RFC3339),
 				"to":   toTime.Format(time.RFC3339),
@@ -413,8 +520,73 @@ func (h *RuleHandler) ExportRules(c *gin.Context) {
 			"queries":       rule.Queries,
 			"enabled":       rule.Enabled,
 			"interval":      rule.Interval,
+			"resolve_after": rule.ResolveAfter,
 			"description":   rule.Description,
 		}
+		if len(rule.Labels) > 0 {
+			cleanRule["labels"] = rule.Labels
+		}
+		if rule.Conditions != nil {
+			cleanRule["conditions"] = rule.Conditions
+		}
+
+		// Add query mode
+		if rule.QueryMode != "" && rule.QueryMode != models.QueryModeConditions {
+			cleanRule["query_mode"] = rule.QueryMode
+		}
+		if len(rule.QueryDSL) > 0 {
+			cleanRule["query_dsl"] = rule.QueryDSL
+		}
+		if rule.QueryString != "" {
+			cleanRule["query_string"] = rule.QueryString
+		}
+		if rule.QueryLanguage != "" {
+			cleanRule["query_language"] = rule.QueryLanguage
+		}
+
+		// Add query window
+		if rule.TimestampField != "" {
+			cleanRule["timestamp_field"] = rule.TimestampField
+		}
+		if rule.WindowTime != "" {
+			cleanRule["window_time"] = rule.WindowTime
+		}
+		if rule.EvaluationDelay != nil {
+			cleanRule["evaluation_delay"] = *rule.EvaluationDelay
+		}
+
+		// Add rule type and its condition
+		if rule.Type != "" {
+			cleanRule["type"] = rule.Type
+		}
+		if rule.Threshold != nil {
+			cleanRule["threshold"] = rule.Threshold
+		}
+		if rule.Aggregation != nil {
+			cleanRule["aggregation"] = rule.Aggregation
+		}
+		if rule.Ratio != nil {
+			cleanRule["ratio"] = rule.Ratio
+		}
+		if rule.Absence != nil {
+			cleanRule["absence"] = rule.Absence
+		}
+
+		// Add notification card layout
+		if rule.DisplayPreset != "" {
+			cleanRule["display_preset"] = rule.DisplayPreset
+		}
+		if len(rule.DisplayFields) > 0 {
+			cleanRule["display_fields"] = rule.DisplayFields
+		}
+
+		// Add alert grouping
+		if len(rule.GroupBy) > 0 {
+			cleanRule["group_by"] = rule.GroupBy
+		}
+		if rule.RenotifyInterval != nil {
+			cleanRule["renotify_interval"] = *rule.RenotifyInterval
+		}
 
 		// Add ES config reference (only ID and name, no sensitive/test data)
 		if rule.ESConfigID != nil {
@@ -435,25 +607,42 @@ func (h *RuleHandler) ExportRules(c *gin.Context) {
 			}
 		}
 
-		// Add Lark config reference (only ID and name, no test data)
-		if rule.LarkConfigID != nil {
-			cleanRule["lark_config_id"] = *rule.LarkConfigID
-			if rule.LarkConfig != nil {
-				cleanRule["lark_config"] = map[string]interface{}{
-					"id":          rule.LarkConfig.ID,
-					"name":        rule.LarkConfig.Name,
-					"webhook_url": rule.LarkConfig.WebhookURL,
-					"enabled":     rule.LarkConfig.Enabled,
-					"description": rule.LarkConfig.Description,
-					"is_default":  rule.LarkConfig.IsDefault,
-					// Exclude: LastTestAt, TestStatus, TestError
+		// Add notification template reference (matched by name on import)
+		if rule.TemplateID != nil {
+			cleanRule["template_id"] = *rule.TemplateID
+			if rule.Template != nil {
+				cleanRule["template"] = map[string]interface{}{
+					"id":   rule.Template.ID,
+					"name": rule.Template.Name,
 				}
 			}
 		}
 
-		// Keep lark_webhook for backward compatibility
-		if rule.LarkWebhook != "" {
-			cleanRule["lark_webhook"] = rule.LarkWebhook
+		// Add notification channel references (matched by name on import)
+		if len(rule.Channels) > 0 {
+			channels := make([]map[string]interface{}, 0, len(rule.Channels))
+			for _, ch := range rule.Channels {
+				channels = append(channels, map[string]interface{}{
+					"id":   ch.ID,
+					"name": ch.Name,
+					"type": ch.Type,
+					// Exclude: Settings (may contain secrets), LastTestAt, TestStatus, TestError
+				})
+			}
+			cleanRule["channels"] = channels
+		}
+
+		// Add schedule references (matched by name on import)
+		if len(rule.Schedules) > 0 {
+			schedules := make([]map[string]interface{}, 0, len(rule.Schedules))
+			for _, s := range rule.Schedules {
+				schedules = append(schedules, map[string]interface{}{
+					"id":   s.ID,
+					"name": s.Name,
+					"kind": s.Kind,
+				})
+			}
+			cleanRule["schedules"] = schedules
 		}
 
 		// Exclude: ID, CreatedAt, UpdatedAt, LastRunTime, RunCount, AlertCount (runtime statistics)
@@ -526,6 +715,7 @@ func (h *RuleHandler) CloneRule(c *gin.Context) {
 		return
 	}
 
+	redactRuleSecrets(clonedRule)
 	c.JSON(http.StatusCreated, gin.H{"data": clonedRule})
 }
 
@@ -568,6 +758,22 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 			errors = append(errors, fmt.Sprintf("Rule '%s': index_pattern is required", rule.Name))
 			continue
 		}
+		if err := rule.ValidateDisplay(); err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
+		if err := rule.ValidateGrouping(); err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
+		if err := rule.ValidateType(); err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
+		if err := rule.ValidateTimeWindow(); err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
 
 		// Resolve ES config by name if es_config is provided, otherwise use es_config_id
 		if rule.ESConfig != nil && rule.ESConfig.Name != "" {
@@ -588,6 +794,10 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 				continue
 			}
 		}
+		if err := h.validateRuleQuery(c.Request.Context(), &rule); err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
 
 		// Resolve Lark config by name if lark_config is provided, otherwise use lark_config_id
 		if rule.LarkConfig != nil && rule.LarkConfig.Name != "" {
@@ -609,18 +819,91 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 			}
 		}
 
+		// Resolve notification template by name if template is provided, otherwise use template_id
+		if rule.Template != nil && rule.Template.Name != "" {
+			tmpl, err := h.templateService.GetByName(rule.Template.Name)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Rule '%s': notification template '%s' not found", rule.Name, rule.Template.Name))
+				continue
+			}
+			rule.TemplateID = &tmpl.ID
+		} else if rule.TemplateID != nil {
+			if _, err := h.templateService.GetByID(*rule.TemplateID); err != nil {
+				errors = append(errors, fmt.Sprintf("Rule '%s': notification template ID %d not found", rule.Name, *rule.TemplateID))
+				continue
+			}
+		}
+		rule.Template = nil
+
+		// Resolve notification channels by name if channels are provided, otherwise use channel_ids
+		if len(rule.Channels) > 0 {
+			channelIDs, err := h.resolveChannelIDs(rule.Channels)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+				continue
+			}
+			rule.ChannelIDs = channelIDs
+			rule.Channels = nil // Clear to avoid association upserts
+		}
+
+		// Resolve schedules by name if schedules are provided, otherwise use schedule_ids
+		if len(rule.Schedules) > 0 {
+			scheduleIDs, err := h.resolveScheduleIDs(rule.Schedules)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+				continue
+			}
+			rule.ScheduleIDs = scheduleIDs
+			rule.Schedules = nil
+		}
+
 		// Check if rule with same name already exists (deduplication by name)
 		existingRule, err := h.service.GetByName(rule.Name)
-		if err == nil && existingRule != nil {
+		exists := err == nil && existingRule != nil
+
+		// Older exports carry a Lark config or webhook, which are stored as a lark channel
+		larkChannelID, err := h.legacyLarkChannel(&rule)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Rule '%s': %v", rule.Name, err))
+			continue
+		}
+		if larkChannelID != 0 {
+			if rule.ChannelIDs == nil && exists {
+				rule.ChannelIDs = channelIDsOf(existingRule.Channels)
+			}
+			rule.ChannelIDs = appendChannelID(rule.ChannelIDs, larkChannelID)
+		}
+
+		if exists {
 			// Rule exists - check if there are actual changes
 			hasChanges := existingRule.IndexPattern != rule.IndexPattern ||
 				existingRule.Interval != rule.Interval ||
+				(rule.ResolveAfter > 0 && existingRule.ResolveAfter != rule.ResolveAfter) ||
 				existingRule.Description != rule.Description ||
 				existingRule.Enabled != rule.Enabled ||
 				!compareQueryConditions(existingRule.Queries, rule.Queries) ||
+				(rule.Conditions != nil && !compareConditionGroups(existingRule.Conditions, rule.Conditions)) ||
+				(rule.QueryMode != "" && existingRule.QueryMode != rule.QueryMode) ||
+				(rule.QueryDSL != nil && !compareQueryDSL(existingRule.QueryDSL, rule.QueryDSL)) ||
+				(rule.QueryString != "" && existingRule.QueryString != rule.QueryString) ||
+				(rule.QueryLanguage != "" && existingRule.QueryLanguage != rule.QueryLanguage) ||
 				!compareOptionalUint(existingRule.ESConfigID, rule.ESConfigID) ||
-				!compareOptionalUint(existingRule.LarkConfigID, rule.LarkConfigID) ||
-				existingRule.LarkWebhook != rule.LarkWebhook
+				(rule.TemplateID != nil && !compareOptionalUint(existingRule.TemplateID, rule.TemplateID)) ||
+				(rule.ChannelIDs != nil && !compareChannelIDs(existingRule.Channels, rule.ChannelIDs)) ||
+				(rule.ScheduleIDs != nil && !compareScheduleIDs(existingRule.Schedules, rule.ScheduleIDs)) ||
+				(rule.DisplayPreset != "" && existingRule.DisplayPreset != rule.DisplayPreset) ||
+				(rule.DisplayFields != nil && !compareDisplayFields(existingRule.DisplayFields, rule.DisplayFields)) ||
+				(rule.Labels != nil && !compareLabels(existingRule.Labels, rule.Labels)) ||
+				(rule.GroupBy != nil && !compareStringList(existingRule.GroupBy, rule.GroupBy)) ||
+				(rule.RenotifyInterval != nil && !compareOptionalInt(existingRule.RenotifyInterval, rule.RenotifyInterval)) ||
+				(rule.TimestampField != "" && existingRule.TimestampField != rule.TimestampField) ||
+				(rule.WindowTime != "" && existingRule.WindowTime != rule.WindowTime) ||
+				(rule.EvaluationDelay != nil && !compareOptionalInt(existingRule.EvaluationDelay, rule.EvaluationDelay)) ||
+				(rule.Type != "" && existingRule.Type != rule.Type) ||
+				(rule.Threshold != nil && (existingRule.Threshold == nil || *existingRule.Threshold != *rule.Threshold)) ||
+				(rule.Aggregation != nil && (existingRule.Aggregation == nil || *existingRule.Aggregation != *rule.Aggregation)) ||
+				(rule.Ratio != nil && !compareRatio(existingRule.Ratio, rule.Ratio)) ||
+				(rule.Absence != nil && (existingRule.Absence == nil || *existingRule.Absence != *rule.Absence))
 
 			if hasChanges {
 				// Update existing rule with new data
@@ -675,6 +958,115 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 	})
 }
 
+// resolveChannelIDs maps exported channel references to local channel IDs (by name first, then by ID)
+func (h *RuleHandler) resolveChannelIDs(channels []models.ChannelConfig) ([]uint, error) {
+	ids := make([]uint, 0, len(channels))
+	for _, ch := range channels {
+		if ch.Name != "" {
+			found, err := h.channelConfigService.GetByName(ch.Name)
+			if err != nil {
+				return nil, fmt.Errorf("channel '%s' not found", ch.Name)
+			}
+			ids = append(ids, found.ID)
+			continue
+		}
+		if _, err := h.channelConfigService.GetByID(ch.ID); err != nil {
+			return nil, fmt.Errorf("channel ID %d not found", ch.ID)
+		}
+		ids = append(ids, ch.ID)
+	}
+	return ids, nil
+}
+
+// resolveScheduleIDs maps exported schedule references to local schedule IDs (by name first, then by ID)
+func (h *RuleHandler) resolveScheduleIDs(schedules []models.Schedule) ([]uint, error) {
+	ids := make([]uint, 0, len(schedules))
+	for _, s := range schedules {
+		if s.Name != "" {
+			found, err := h.scheduleService.GetByName(s.Name)
+			if err != nil {
+				return nil, fmt.Errorf("schedule '%s' not found", s.Name)
+			}
+			ids = append(ids, found.ID)
+			continue
+		}
+		if _, err := h.scheduleService.GetByID(s.ID); err != nil {
+			return nil, fmt.Errorf("schedule ID %d not found", s.ID)
+		}
+		ids = append(ids, s.ID)
+	}
+	return ids, nil
+}
+
+// legacyLarkChannel returns the lark channel standing in for an imported rule's Lark
+// config or direct webhook (the config takes precedence), or 0 when it sets neither.
+// The legacy fields are cleared once converted.
+func (h *RuleHandler) legacyLarkChannel(rule *models.Rule) (uint, error) {
+	configID, webhookURL := rule.LarkConfigID, rule.LarkWebhook
+	rule.LarkConfigID, rule.LarkConfig, rule.LarkWebhook = nil, nil, ""
+
+	switch {
+	case configID != nil:
+		return h.larkConfigService.ChannelForConfig(*configID)
+	case webhookURL != "":
+		return h.larkConfigService.ChannelForWebhook(rule.Name, webhookURL)
+	}
+	return 0, nil
+}
+
+// channelIDsOf returns the IDs of linked channels
+func channelIDsOf(channels []models.ChannelConfig) []uint {
+	ids := make([]uint, 0, len(channels))
+	for _, ch := range channels {
+		ids = append(ids, ch.ID)
+	}
+	return ids
+}
+
+// appendChannelID adds a channel ID to a list unless it is already there
+func appendChannelID(ids []uint, id uint) []uint {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// compareScheduleIDs compares linked schedules with a list of schedule IDs (order-insensitive)
+func compareScheduleIDs(schedules []models.Schedule, ids []uint) bool {
+	if len(schedules) != len(ids) {
+		return false
+	}
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	for _, s := range schedules {
+		if !set[s.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareChannelIDs compares linked channels with a list of channel IDs (order-insensitive)
+func compareChannelIDs(channels []models.ChannelConfig, ids []uint) bool {
+	if len(channels) != len(ids) {
+		return false
+	}
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	for _, ch := range channels {
+		if !set[ch.ID] {
+			return false
+		}
+	}
+	return true
+}
+
 // compareOptionalUint compares two optional uint pointers
 func compareOptionalUint(a, b *uint) bool {
 	if a == nil && b == nil {
@@ -686,6 +1078,43 @@ func compareOptionalUint(a, b *uint) bool {
 	return *a == *b
 }
 
+// compareOptionalInt compares two optional int pointers
+func compareOptionalInt(a, b *int) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return *a == *b
+}
+
+// compareStringList compares two string lists, order sensitive
+func compareStringList(a, b models.StringList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compareLabels compares two label maps
+func compareLabels(a, b models.Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
 // compareQueryConditions compares two QueryConditions slices
 func compareQueryConditions(a, b models.QueryConditions) bool {
 	if len(a) != len(b) {
@@ -708,3 +1137,53 @@ func compareQueryConditions(a, b models.QueryConditions) bool {
 	}
 	return true
 }
+
+// compareConditionGroups compares two condition trees by their JSON encoding
+func compareConditionGroups(a, b *models.ConditionGroup) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+// compareQueryDSL compares two raw query objects by their JSON encoding
+func compareQueryDSL(a, b models.QueryDSL) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+// compareRatio compares two ratio configurations, nil only equals nil
+func compareRatio(a, b *models.RatioConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return compareQueryConditions(a.Numerator, b.Numerator) &&
+		compareQueryConditions(a.Denominator, b.Denominator) &&
+		a.Operator == b.Operator &&
+		a.Threshold == b.Threshold &&
+		a.MinDenominator == b.MinDenominator &&
+		a.Window == b.Window
+}
+
+// compareDisplayFields compares two display field lists, order sensitive
+func compareDisplayFields(a, b models.DisplayFields) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDisplayPresets returns the built-in notification card layouts
+// @Summary Get built-in display field presets
+// @Tags rules
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/rules/display-presets [get]
+func (h *RuleHandler) GetDisplayPresets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": models.DisplayPresets})
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/rule"
+	"github.com/kk/elk-helper/backend/internal/service/schedule"
+)
+
+type ScheduleHandler struct {
+	service     *schedule.Service
+	ruleService *rule.Service
+}
+
+func NewScheduleHandler() *ScheduleHandler {
+	return &ScheduleHandler{
+		service:     schedule.NewService(),
+		ruleService: rule.NewService(),
+	}
+}
+
+// ruleWindowStatus is a rule currently inside one or more of its schedule windows
+type ruleWindowStatus struct {
+	RuleID    uint              `json:"rule_id"`
+	RuleName  string            `json:"rule_name"`
+	Enabled   bool              `json:"enabled"`
+	Running   bool              `json:"running"` // false 表示当前被时间计划暂停
+	Schedules []models.Schedule `json:"schedules"`
+}
+
+// GetSchedules returns all schedules
+// @Summary Get all schedules
+// @Tags schedules
+// @Produce json
+// @Success 200 {array} models.Schedule
+// @Router /api/v1/schedules [get]
+func (h *ScheduleHandler) GetSchedules(c *gin.Context) {
+	schedules, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schedules})
+}
+
+// GetSchedule returns a schedule by ID
+// @Summary Get schedule by ID
+// @Tags schedules
+// @Param id path int true "Schedule ID"
+// @Produce json
+// @Success 200 {object} models.Schedule
+// @Router /api/v1/schedules/{id} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	s, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": s})
+}
+
+// CreateSchedule creates a new schedule
+// @Summary Create a new schedule
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param schedule body models.Schedule true "Schedule data"
+// @Success 201 {object} models.Schedule
+// @Router /api/v1/schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var s models.Schedule
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if err := s.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Create(&s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": s})
+}
+
+// UpdateSchedule updates an existing schedule
+// @Summary Update a schedule
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param schedule body models.Schedule true "Schedule data"
+// @Success 200 {object} models.Schedule
+// @Router /api/v1/schedules/{id} [put]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	var s models.Schedule
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.service.GetByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if err := s.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Update(uint(id), &s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// DeleteSchedule deletes a schedule
+// @Summary Delete a schedule
+// @Tags schedules
+// @Param id path int true "Schedule ID"
+// @Success 204
+// @Router /api/v1/schedules/{id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRulesInWindow lists the rules currently inside a window of their schedules
+// @Summary List rules currently inside a schedule window
+// @Tags schedules
+// @Produce json
+// @Success 200 {array} ruleWindowStatus
+// @Router /api/v1/schedules/rules-in-window [get]
+func (h *ScheduleHandler) GetRulesInWindow(c *gin.Context) {
+	rules, err := h.ruleService.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	result := make([]ruleWindowStatus, 0)
+	for i := range rules {
+		allowed, inside := rules[i].ScheduleAllows(now)
+		if len(inside) == 0 {
+			continue
+		}
+		result = append(result, ruleWindowStatus{
+			RuleID:    rules[i].ID,
+			RuleName:  rules[i].Name,
+			Enabled:   rules[i].Enabled,
+			Running:   rules[i].Enabled && allowed,
+			Schedules: inside,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
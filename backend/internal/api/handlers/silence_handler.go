@@ -0,0 +1,213 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/silence"
+)
+
+type SilenceHandler struct {
+	service *silence.Service
+}
+
+func NewSilenceHandler() *SilenceHandler {
+	return &SilenceHandler{
+		service: silence.NewService(),
+	}
+}
+
+// GetSilences returns silences
+// @Summary Get silences
+// @Tags silences
+// @Param state query string false "State: pending, active or expired"
+// @Produce json
+// @Success 200 {array} models.Silence
+// @Router /api/v1/silences [get]
+func (h *SilenceHandler) GetSilences(c *gin.Context) {
+	state := c.Query("state")
+	switch models.SilenceState(state) {
+	case "", models.SilenceStatePending, models.SilenceStateActive, models.SilenceStateExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+		return
+	}
+
+	silences, err := h.service.GetAll(state)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": silences})
+}
+
+// GetSilence returns a silence by ID
+// @Summary Get silence by ID
+// @Tags silences
+// @Param id path int true "Silence ID"
+// @Produce json
+// @Success 200 {object} models.Silence
+// @Router /api/v1/silences/{id} [get]
+func (h *SilenceHandler) GetSilence(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid silence ID"})
+		return
+	}
+
+	s, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": s})
+}
+
+// CreateSilence creates a new silence; starts_at defaults to now
+// @Summary Create a new silence
+// @Tags silences
+// @Accept json
+// @Produce json
+// @Param silence body models.Silence true "Silence data"
+// @Success 201 {object} models.Silence
+// @Router /api/v1/silences [post]
+func (h *SilenceHandler) CreateSilence(c *gin.Context) {
+	var s models.Silence
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if s.StartsAt.IsZero() {
+		s.StartsAt = time.Now()
+	}
+	if err := s.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s.CreatedBy = c.GetString("username")
+
+	if err := h.service.Create(&s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": s})
+}
+
+// UpdateSilence updates the time range, comment and matchers of a silence
+// @Summary Update a silence
+// @Tags silences
+// @Accept json
+// @Produce json
+// @Param id path int true "Silence ID"
+// @Param silence body models.Silence true "Silence data"
+// @Success 200 {object} models.Silence
+// @Router /api/v1/silences/{id} [put]
+func (h *SilenceHandler) UpdateSilence(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid silence ID"})
+		return
+	}
+
+	var s models.Silence
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "silence not found"})
+		return
+	}
+	if existing.State == models.SilenceStateExpired {
+		c.JSON(http.StatusConflict, gin.H{"error": "expired silences cannot be updated"})
+		return
+	}
+
+	if s.StartsAt.IsZero() {
+		s.StartsAt = existing.StartsAt
+	}
+	if err := s.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Update(uint(id), &s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// ExpireSilence ends a silence immediately
+// @Summary Expire a silence
+// @Tags silences
+// @Param id path int true "Silence ID"
+// @Produce json
+// @Success 200 {object} models.Silence
+// @Router /api/v1/silences/{id}/expire [post]
+func (h *SilenceHandler) ExpireSilence(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid silence ID"})
+		return
+	}
+
+	ok, err := h.service.Expire(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "silence not found or already expired"})
+		return
+	}
+
+	s, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": s})
+}
+
+// DeleteSilence deletes a silence
+// @Summary Delete a silence
+// @Tags silences
+// @Param id path int true "Silence ID"
+// @Success 204
+// @Router /api/v1/silences/{id} [delete]
+func (h *SilenceHandler) DeleteSilence(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid silence ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/service/alert"
 	"github.com/kk/elk-helper/backend/internal/service/esconfig"
+	"github.com/kk/elk-helper/backend/internal/service/query"
 	"github.com/kk/elk-helper/backend/internal/service/rule"
 )
 
@@ -75,12 +76,13 @@ func (h *StatusHandler) getESStatusInfo() gin.H {
 	configs, err := h.esConfigService.GetAll()
 	if err != nil {
 		return gin.H{
-			"status":        "unknown",
-			"total":         0,
-			"success_count": 0,
-			"failed_count":  0,
-			"unknown_count": 0,
-			"details":       []gin.H{},
+			"status":             "unknown",
+			"total":              0,
+			"success_count":      0,
+			"failed_count":       0,
+			"unknown_count":      0,
+			"breaker_open_count": 0,
+			"details":            []gin.H{},
 		}
 	}
 
@@ -95,12 +97,13 @@ func (h *StatusHandler) getESStatusInfo() gin.H {
 	// If no enabled configurations
 	if len(enabledConfigs) == 0 {
 		return gin.H{
-			"status":        "not_configured",
-			"total":         0,
-			"success_count": 0,
-			"failed_count":  0,
-			"unknown_count": 0,
-			"details":       []gin.H{},
+			"status":             "not_configured",
+			"total":              0,
+			"success_count":      0,
+			"failed_count":       0,
+			"unknown_count":      0,
+			"breaker_open_count": 0,
+			"details":            []gin.H{},
 		}
 	}
 
@@ -108,6 +111,7 @@ func (h *StatusHandler) getESStatusInfo() gin.H {
 	successCount := 0
 	failedCount := 0
 	unknownCount := 0
+	breakerOpenCount := 0
 	var details []gin.H
 
 	for _, config := range enabledConfigs {
@@ -124,11 +128,21 @@ func (h *StatusHandler) getESStatusInfo() gin.H {
 			status = "unknown"
 		}
 
+		// Breaker state of the shared client; closed until a rule has queried the data source
+		breaker, ok := query.GuardStatusForConfig(config.ID)
+		if !ok {
+			breaker = query.GuardStatus{State: query.BreakerClosed}
+		}
+		if breaker.State != query.BreakerClosed {
+			breakerOpenCount++
+		}
+
 		details = append(details, gin.H{
-			"id":     config.ID,
-			"name":   config.Name,
-			"url":    config.URL,
-			"status": status,
+			"id":      config.ID,
+			"name":    config.Name,
+			"url":     config.URL,
+			"status":  status,
+			"breaker": breaker,
 		})
 	}
 
@@ -143,11 +157,12 @@ func (h *StatusHandler) getESStatusInfo() gin.H {
 	}
 
 	return gin.H{
-		"status":        overallStatus,
-		"total":         len(enabledConfigs),
-		"success_count": successCount,
-		"failed_count":  failedCount,
-		"unknown_count": unknownCount,
-		"details":       details,
+		"status":             overallStatus,
+		"total":              len(enabledConfigs),
+		"success_count":      successCount,
+		"failed_count":       failedCount,
+		"unknown_count":      unknownCount,
+		"breaker_open_count": breakerOpenCount,
+		"details":            details,
 	}
 }
@@ -86,6 +86,7 @@ func SetupRoutes(r *gin.Engine) {
 				rules.POST("/test", ruleHandler.TestRule)
 				rules.POST("/batch-delete", ruleHandler.BatchDeleteRules)
 				rules.GET("/export", ruleHandler.ExportRules)
+				rules.GET("/display-presets", ruleHandler.GetDisplayPresets)
 				rules.POST("/import", ruleHandler.ImportRules)
 			}
 
@@ -99,6 +100,8 @@ func SetupRoutes(r *gin.Engine) {
 				alerts.GET("/rule-timeseries", alertHandler.GetRuleTimeSeriesStats)
 				alerts.GET("/:id", alertHandler.GetAlert)
 				alerts.DELETE("/:id", alertHandler.DeleteAlert)
+				alerts.POST("/:id/acknowledge", alertHandler.AcknowledgeAlert)
+				alerts.POST("/:id/resolve", alertHandler.ResolveAlert)
 				alerts.POST("/batch-delete", alertHandler.BatchDeleteAlerts)
 			}
 
@@ -136,6 +139,55 @@ func SetupRoutes(r *gin.Engine) {
 				larkConfigs.POST("/:id/set-default", larkConfigHandler.SetDefaultLarkConfig)
 			}
 
+			// Notification channel routes
+			channelConfigHandler := handlers.NewChannelConfigHandler()
+			channelConfigs := protected.Group("/channel-configs")
+			{
+				channelConfigs.GET("", channelConfigHandler.GetChannelConfigs)
+				channelConfigs.GET("/:id", channelConfigHandler.GetChannelConfig)
+				channelConfigs.POST("", channelConfigHandler.CreateChannelConfig)
+				channelConfigs.PUT("/:id", channelConfigHandler.UpdateChannelConfig)
+				channelConfigs.DELETE("/:id", channelConfigHandler.DeleteChannelConfig)
+				channelConfigs.POST("/:id/test", channelConfigHandler.TestChannelConfig)
+			}
+
+			// Notification template routes
+			notificationTemplateHandler := handlers.NewNotificationTemplateHandler()
+			notificationTemplates := protected.Group("/notification-templates")
+			{
+				notificationTemplates.GET("", notificationTemplateHandler.GetNotificationTemplates)
+				notificationTemplates.GET("/default", notificationTemplateHandler.GetDefaultNotificationTemplate)
+				notificationTemplates.POST("/preview", notificationTemplateHandler.PreviewNotificationTemplate)
+				notificationTemplates.GET("/:id", notificationTemplateHandler.GetNotificationTemplate)
+				notificationTemplates.POST("", notificationTemplateHandler.CreateNotificationTemplate)
+				notificationTemplates.PUT("/:id", notificationTemplateHandler.UpdateNotificationTemplate)
+				notificationTemplates.DELETE("/:id", notificationTemplateHandler.DeleteNotificationTemplate)
+			}
+
+			// Silence routes
+			silenceHandler := handlers.NewSilenceHandler()
+			silences := protected.Group("/silences")
+			{
+				silences.GET("", silenceHandler.GetSilences)
+				silences.GET("/:id", silenceHandler.GetSilence)
+				silences.POST("", silenceHandler.CreateSilence)
+				silences.PUT("/:id", silenceHandler.UpdateSilence)
+				silences.POST("/:id/expire", silenceHandler.ExpireSilence)
+				silences.DELETE("/:id", silenceHandler.DeleteSilence)
+			}
+
+			// Schedule routes
+			scheduleHandler := handlers.NewScheduleHandler()
+			schedules := protected.Group("/schedules")
+			{
+				schedules.GET("", scheduleHandler.GetSchedules)
+				schedules.GET("/rules-in-window", scheduleHandler.GetRulesInWindow)
+				schedules.GET("/:id", scheduleHandler.GetSchedule)
+				schedules.POST("", scheduleHandler.CreateSchedule)
+				schedules.PUT("/:id", scheduleHandler.UpdateSchedule)
+				schedules.DELETE("/:id", scheduleHandler.DeleteSchedule)
+			}
+
 			// System Config routes
 			systemConfigHandler := handlers.NewSystemConfigHandler()
 			systemConfigs := protected.Group("/system-config")
@@ -54,6 +54,12 @@ type ElasticsearchConfig struct {
 	SkipVerify          bool
 	CACertificate       string
 	QueryTimeoutSeconds int
+	// MaxConcurrency caps concurrent queries per data source; 0 uses half of Worker.MaxConcurrency.
+	MaxConcurrency int
+	// BreakerFailures is the number of consecutive failed requests that opens a data source's circuit breaker.
+	BreakerFailures int
+	// BreakerCooldownSeconds is how long an open circuit breaker rejects queries before a probe.
+	BreakerCooldownSeconds int
 }
 
 // WorkerConfig represents worker configuration
@@ -110,13 +116,16 @@ func Load() error {
 			QueryTimeoutSeconds: parseIntWithDefault(getEnv("DB_QUERY_TIMEOUT_SECONDS", "5"), 5),
 		},
 		ES: ElasticsearchConfig{
-			URL:                 getEnv("ES_URL", "http://localhost:9200"),
-			Username:            getEnv("ES_USERNAME", ""),
-			Password:            getEnv("ES_PASSWORD", ""),
-			UseSSL:              parseBoolWithDefault(getEnv("ES_USE_SSL", ""), strings.HasPrefix(getEnv("ES_URL", "http://localhost:9200"), "https://")),
-			SkipVerify:          parseBoolWithDefault(getEnv("ES_SKIP_VERIFY", ""), false),
-			CACertificate:       getEnv("ES_CA_CERTIFICATE", ""),
-			QueryTimeoutSeconds: parseIntWithDefault(getEnv("ES_QUERY_TIMEOUT_SECONDS", "30"), 30),
+			URL:                    getEnv("ES_URL", "http://localhost:9200"),
+			Username:               getEnv("ES_USERNAME", ""),
+			Password:               getEnv("ES_PASSWORD", ""),
+			UseSSL:                 parseBoolWithDefault(getEnv("ES_USE_SSL", ""), strings.HasPrefix(getEnv("ES_URL", "http://localhost:9200"), "https://")),
+			SkipVerify:             parseBoolWithDefault(getEnv("ES_SKIP_VERIFY", ""), false),
+			CACertificate:          getEnv("ES_CA_CERTIFICATE", ""),
+			QueryTimeoutSeconds:    parseIntWithDefault(getEnv("ES_QUERY_TIMEOUT_SECONDS", "30"), 30),
+			MaxConcurrency:         parseIntWithDefault(getEnv("ES_MAX_CONCURRENCY", "0"), 0),
+			BreakerFailures:        parseIntWithDefault(getEnv("ES_BREAKER_FAILURES", "5"), 5),
+			BreakerCooldownSeconds: parseIntWithDefault(getEnv("ES_BREAKER_COOLDOWN_SECONDS", "60"), 60),
 		},
 		Worker: WorkerConfig{
 			Enabled:                 getEnv("WORKER_ENABLED", "true") == "true",
@@ -0,0 +1,12 @@
+-- 000003_add_channel_configs.down.sql
+-- 回滚通知渠道配置表
+
+DROP TRIGGER IF EXISTS update_channel_configs_updated_at ON channel_configs;
+
+DROP INDEX IF EXISTS idx_rule_channels_channel_config_id;
+DROP INDEX IF EXISTS idx_channel_configs_type;
+DROP INDEX IF EXISTS idx_channel_configs_deleted_at;
+DROP INDEX IF EXISTS idx_channel_configs_name;
+
+DROP TABLE IF EXISTS rule_channels;
+DROP TABLE IF EXISTS channel_configs;
@@ -0,0 +1,42 @@
+-- 000003_add_channel_configs.up.sql
+-- 添加通用通知渠道配置表及规则-渠道关联表
+
+-- 通知渠道配置表
+CREATE TABLE IF NOT EXISTS channel_configs (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    settings TEXT,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_test_at TIMESTAMPTZ,
+    test_status VARCHAR(50) NOT NULL DEFAULT 'unknown',
+    test_error TEXT
+);
+
+-- 规则-渠道关联表（多对多）
+CREATE TABLE IF NOT EXISTS rule_channels (
+    rule_id BIGINT NOT NULL REFERENCES rules(id) ON DELETE CASCADE,
+    channel_config_id BIGINT NOT NULL REFERENCES channel_configs(id) ON DELETE CASCADE,
+    PRIMARY KEY (rule_id, channel_config_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channel_configs_name ON channel_configs(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_channel_configs_deleted_at ON channel_configs(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_channel_configs_type ON channel_configs(type);
+CREATE INDEX IF NOT EXISTS idx_rule_channels_channel_config_id ON rule_channels(channel_config_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_channel_configs_updated_at') THEN
+        CREATE TRIGGER update_channel_configs_updated_at
+            BEFORE UPDATE ON channel_configs
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+-- 000004_add_lark_sign_secret.down.sql
+-- 回滚飞书签名密钥字段
+
+ALTER TABLE lark_configs DROP COLUMN IF EXISTS sign_secret;
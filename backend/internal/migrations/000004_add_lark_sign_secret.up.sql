@@ -0,0 +1,4 @@
+-- 000004_add_lark_sign_secret.up.sql
+-- 飞书自定义机器人签名校验：添加签名密钥字段（加密存储）
+
+ALTER TABLE lark_configs ADD COLUMN IF NOT EXISTS sign_secret TEXT;
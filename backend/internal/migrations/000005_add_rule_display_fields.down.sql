@@ -0,0 +1,5 @@
+-- 000005_add_rule_display_fields.down.sql
+-- 回滚规则通知卡片字段配置
+
+ALTER TABLE rules DROP COLUMN IF EXISTS display_fields;
+ALTER TABLE rules DROP COLUMN IF EXISTS display_preset;
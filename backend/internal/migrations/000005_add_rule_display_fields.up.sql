@@ -0,0 +1,5 @@
+-- 000005_add_rule_display_fields.up.sql
+-- 规则通知卡片字段配置：展示预设 + 自定义展示字段
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS display_preset VARCHAR(50) NOT NULL DEFAULT 'auto';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS display_fields TEXT;
@@ -0,0 +1,8 @@
+-- 000006_add_notification_templates.down.sql
+-- 回滚通知模板
+
+ALTER TABLE channel_configs DROP COLUMN IF EXISTS template_id;
+ALTER TABLE rules DROP COLUMN IF EXISTS template_id;
+
+DROP TRIGGER IF EXISTS update_notification_templates_updated_at ON notification_templates;
+DROP TABLE IF EXISTS notification_templates;
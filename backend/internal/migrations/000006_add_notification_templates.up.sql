@@ -0,0 +1,35 @@
+-- 000006_add_notification_templates.up.sql
+-- 添加通知模板表，规则和通知渠道可分别指定模板
+
+CREATE TABLE IF NOT EXISTS notification_templates (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    name VARCHAR(255) NOT NULL,
+    title TEXT,
+    color VARCHAR(50),
+    body TEXT,
+    description TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_name ON notification_templates(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notification_templates_deleted_at ON notification_templates(deleted_at);
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS template_id BIGINT REFERENCES notification_templates(id) ON DELETE SET NULL;
+ALTER TABLE channel_configs ADD COLUMN IF NOT EXISTS template_id BIGINT REFERENCES notification_templates(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_rules_template_id ON rules(template_id);
+CREATE INDEX IF NOT EXISTS idx_channel_configs_template_id ON channel_configs(template_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_notification_templates_updated_at') THEN
+        CREATE TRIGGER update_notification_templates_updated_at
+            BEFORE UPDATE ON notification_templates
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END
+$$;
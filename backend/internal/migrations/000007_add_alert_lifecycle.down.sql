@@ -0,0 +1,15 @@
+-- 000007_add_alert_lifecycle.down.sql
+-- 回滚告警生命周期
+
+ALTER TABLE rules DROP COLUMN IF EXISTS resolve_after;
+
+DROP INDEX IF EXISTS idx_alerts_rule_id_state;
+DROP INDEX IF EXISTS idx_alerts_state;
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS acknowledged_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS acknowledged_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS last_fired_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS clean_count;
+ALTER TABLE alerts DROP COLUMN IF EXISTS fire_count;
+ALTER TABLE alerts DROP COLUMN IF EXISTS state;
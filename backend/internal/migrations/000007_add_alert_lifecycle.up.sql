@@ -0,0 +1,18 @@
+-- 000007_add_alert_lifecycle.up.sql
+-- 告警生命周期：firing -> acknowledged -> resolved，连续命中合并为同一条告警
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS state VARCHAR(50) NOT NULL DEFAULT 'firing';
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS fire_count INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS clean_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS last_fired_at TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS acknowledged_by VARCHAR(255);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+
+-- 历史告警视为已恢复，避免新的命中合并到旧告警
+UPDATE alerts SET state = 'resolved', resolved_at = created_at, last_fired_at = created_at WHERE resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_state ON alerts(state);
+CREATE INDEX IF NOT EXISTS idx_alerts_rule_id_state ON alerts(rule_id, state);
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS resolve_after INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+-- 000008_add_alert_grouping.down.sql
+
+ALTER TABLE alerts DROP COLUMN IF EXISTS last_notified_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS groups;
+
+ALTER TABLE rules DROP COLUMN IF EXISTS renotify_interval;
+ALTER TABLE rules DROP COLUMN IF EXISTS group_by;
@@ -0,0 +1,8 @@
+-- 000008_add_alert_grouping.up.sql
+-- 告警分组与去重：按 group_by 字段计算指纹，同一分组在 renotify_interval 内不重复通知
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS group_by TEXT;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS renotify_interval INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS groups TEXT;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS last_notified_at TIMESTAMPTZ;
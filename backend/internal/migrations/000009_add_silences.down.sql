@@ -0,0 +1,10 @@
+-- 000009_add_silences.down.sql
+-- 回滚静默
+
+DROP INDEX IF EXISTS idx_alerts_silence_id;
+ALTER TABLE alerts DROP COLUMN IF EXISTS silence_id;
+
+ALTER TABLE rules DROP COLUMN IF EXISTS labels;
+
+DROP TRIGGER IF EXISTS update_silences_updated_at ON silences;
+DROP TABLE IF EXISTS silences;
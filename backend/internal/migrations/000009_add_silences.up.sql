@@ -0,0 +1,34 @@
+-- 000009_add_silences.up.sql
+-- 添加静默表：按规则名称、规则标签或日志字段匹配，生效期间告警照常记录但不发送通知
+
+CREATE TABLE IF NOT EXISTS silences (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_by VARCHAR(255),
+    comment TEXT,
+    matchers TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_silences_ends_at ON silences(ends_at);
+CREATE INDEX IF NOT EXISTS idx_silences_deleted_at ON silences(deleted_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_silences_updated_at') THEN
+        CREATE TRIGGER update_silences_updated_at
+            BEFORE UPDATE ON silences
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END
+$$;
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS labels TEXT;
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS silence_id BIGINT REFERENCES silences(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_alerts_silence_id ON alerts(silence_id);
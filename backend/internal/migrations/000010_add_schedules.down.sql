@@ -0,0 +1,7 @@
+-- 000010_add_schedules.down.sql
+-- 回滚时间计划
+
+DROP TABLE IF EXISTS rule_schedules;
+
+DROP TRIGGER IF EXISTS update_schedules_updated_at ON schedules;
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,37 @@
+-- 000010_add_schedules.up.sql
+-- 添加时间计划表：生效时段（active）与维护窗口（maintenance），规则可关联多个时间计划
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+    windows TEXT NOT NULL,
+    description TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedules_name ON schedules(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_schedules_deleted_at ON schedules(deleted_at);
+
+CREATE TABLE IF NOT EXISTS rule_schedules (
+    rule_id BIGINT NOT NULL REFERENCES rules(id) ON DELETE CASCADE,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    PRIMARY KEY (rule_id, schedule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rule_schedules_schedule_id ON rule_schedules(schedule_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_schedules_updated_at') THEN
+        CREATE TRIGGER update_schedules_updated_at
+            BEFORE UPDATE ON schedules
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+-- 000011_add_rule_threshold.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS threshold;
+ALTER TABLE rules DROP COLUMN IF EXISTS type;
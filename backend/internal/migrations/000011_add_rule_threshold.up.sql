@@ -0,0 +1,5 @@
+-- 000011_add_rule_threshold.up.sql
+-- 规则类型：match（命中即告警，默认）与 threshold（时间窗口内命中数满足阈值时告警）
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS type VARCHAR(50) NOT NULL DEFAULT 'match';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS threshold TEXT;
@@ -0,0 +1,3 @@
+-- 000012_add_rule_aggregation.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS aggregation;
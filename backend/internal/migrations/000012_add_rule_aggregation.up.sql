@@ -0,0 +1,4 @@
+-- 000012_add_rule_aggregation.up.sql
+-- 聚合规则：type 为 aggregation 时按 terms 分桶计算指标（count / cardinality / 百分位等），桶指标满足条件时告警
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS aggregation TEXT;
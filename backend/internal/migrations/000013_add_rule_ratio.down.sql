@@ -0,0 +1,3 @@
+-- 000013_add_rule_ratio.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS ratio;
@@ -0,0 +1,4 @@
+-- 000013_add_rule_ratio.up.sql
+-- 比例规则：type 为 ratio 时在同一时间窗口内分别统计分子、分母查询的命中数，比例满足条件时告警
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS ratio TEXT;
@@ -0,0 +1,3 @@
+-- 000014_add_rule_absence.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS absence;
@@ -0,0 +1,4 @@
+-- 000014_add_rule_absence.up.sql
+-- 缺失（心跳）规则：type 为 absence 时，回看窗口内日志少于期望条数即告警，日志恢复后自动恢复
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS absence TEXT;
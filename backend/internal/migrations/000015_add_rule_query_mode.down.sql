@@ -0,0 +1,6 @@
+-- 000015_add_rule_query_mode.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS query_language;
+ALTER TABLE rules DROP COLUMN IF EXISTS query_string;
+ALTER TABLE rules DROP COLUMN IF EXISTS query_dsl;
+ALTER TABLE rules DROP COLUMN IF EXISTS query_mode;
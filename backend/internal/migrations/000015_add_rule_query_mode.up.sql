@@ -0,0 +1,7 @@
+-- 000015_add_rule_query_mode.up.sql
+-- 规则查询模式：conditions（queries 条件列表，默认）、dsl（原始 Query DSL）与 query_string（Lucene / KQL 查询字符串）
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS query_mode VARCHAR(50) NOT NULL DEFAULT 'conditions';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS query_dsl TEXT;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS query_string TEXT;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS query_language VARCHAR(50);
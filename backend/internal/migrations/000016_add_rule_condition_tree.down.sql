@@ -0,0 +1,3 @@
+-- 000016_add_rule_condition_tree.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS conditions;
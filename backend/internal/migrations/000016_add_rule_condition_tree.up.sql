@@ -0,0 +1,29 @@
+-- 000016_add_rule_condition_tree.up.sql
+-- 条件树：规则条件由扁平的 queries 列表改为可嵌套的分组（all / any / none）
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS conditions TEXT;
+
+-- 将已有规则的扁平条件转换为语义相同的条件树：
+-- logic 为 and 的条件全部满足，其余条件（or 或未设置）组成一个至少满足一个的分组
+UPDATE rules r SET conditions = (
+    SELECT jsonb_strip_nulls(jsonb_build_object(
+        'match', 'all',
+        'conditions', (
+            SELECT jsonb_agg(q - 'logic')
+            FROM jsonb_array_elements(r.queries::jsonb) q
+            WHERE q->>'logic' = 'and'
+        ),
+        'groups', (
+            SELECT jsonb_build_array(jsonb_build_object('match', 'any', 'conditions', jsonb_agg(q - 'logic')))
+            FROM jsonb_array_elements(r.queries::jsonb) q
+            WHERE COALESCE(q->>'logic', '') <> 'and'
+            HAVING COUNT(*) > 0
+        )
+    ))::text
+)
+WHERE conditions IS NULL
+  AND CASE
+        WHEN queries IS NULL OR queries = '' THEN FALSE
+        WHEN jsonb_typeof(queries::jsonb) <> 'array' THEN FALSE
+        ELSE jsonb_array_length(queries::jsonb) > 0
+      END;
@@ -0,0 +1,5 @@
+-- 000017_add_rule_time_window.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS evaluation_delay;
+ALTER TABLE rules DROP COLUMN IF EXISTS window_time;
+ALTER TABLE rules DROP COLUMN IF EXISTS timestamp_field;
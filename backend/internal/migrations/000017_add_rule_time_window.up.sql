@@ -0,0 +1,6 @@
+-- 000017_add_rule_time_window.up.sql
+-- 规则查询窗口：事件时间字段、按写入时间（event.ingested）划分窗口，以及补偿写入延迟的评估延迟
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS timestamp_field VARCHAR(255);
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS window_time VARCHAR(50) NOT NULL DEFAULT 'event';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS evaluation_delay INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 000018_migrate_lark_configs_to_channels.down.sql
+
+DROP INDEX IF EXISTS idx_lark_configs_channel_config_id;
+ALTER TABLE lark_configs DROP COLUMN IF EXISTS channel_config_id;
@@ -0,0 +1,6 @@
+-- 000018_migrate_lark_configs_to_channels.up.sql
+-- 飞书配置迁移为通用通知渠道：记录每个飞书配置对应的 lark 渠道。
+-- 数据迁移（webhook 与签名密钥为应用密钥加密存储）在服务启动时由 lark_config.MigrateToChannels 完成。
+
+ALTER TABLE lark_configs ADD COLUMN IF NOT EXISTS channel_config_id BIGINT REFERENCES channel_configs(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_lark_configs_channel_config_id ON lark_configs(channel_config_id);
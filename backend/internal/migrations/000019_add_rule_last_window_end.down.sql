@@ -0,0 +1,3 @@
+-- 000019_add_rule_last_window_end.down.sql
+
+ALTER TABLE rules DROP COLUMN IF EXISTS last_window_end;
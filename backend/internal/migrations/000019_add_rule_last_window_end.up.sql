@@ -0,0 +1,4 @@
+-- 000019_add_rule_last_window_end.up.sql
+-- 记录规则上次评估的查询窗口终点，下次从该时间继续查询，修改评估延迟时不会漏查或重复查询
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS last_window_end TIMESTAMPTZ;
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AggregationMetric is the metric computed per bucket of an aggregation rule
+type AggregationMetric string
+
+const (
+	AggregationMetricCount       AggregationMetric = "count"       // 桶内日志数
+	AggregationMetricCardinality AggregationMetric = "cardinality" // 字段去重数
+	AggregationMetricAvg         AggregationMetric = "avg"
+	AggregationMetricSum         AggregationMetric = "sum"
+	AggregationMetricMin         AggregationMetric = "min"
+	AggregationMetricMax         AggregationMetric = "max"
+	AggregationMetricPercentiles AggregationMetric = "percentiles" // 百分位数，配合 percentile 使用
+)
+
+// maxAggregationBuckets caps the terms size of aggregation rules
+const maxAggregationBuckets = 500
+
+// AggregationConfig alerts on the buckets whose metric satisfies
+// "metric operator threshold", e.g. count of each client.ip > 1000 or
+// p99 of upstream_response_time > 2
+type AggregationConfig struct {
+	GroupBy    string            `json:"group_by,omitempty"`   // terms 分桶字段（keyword 类型）；为空时整个窗口为一个桶
+	Size       int               `json:"size,omitempty"`       // 参与评估的桶数量，默认 10
+	Metric     AggregationMetric `json:"metric"`               // count, cardinality, avg, sum, min, max, percentiles
+	Field      string            `json:"field,omitempty"`      // 指标字段，count 不需要
+	Percentile float64           `json:"percentile,omitempty"` // percentiles 的百分位，如 99
+	Operator   CompareOp         `json:"operator"`
+	Threshold  float64           `json:"threshold"`
+	Window     int               `json:"window,omitempty"` // 回看窗口（秒），与执行间隔无关；为 0 时使用规则间隔
+}
+
+// Value implements driver.Valuer
+func (ac AggregationConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(ac)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (ac *AggregationConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, ac)
+}
+
+// Validate checks the metric, field, bucket size and condition of an aggregation
+func (ac *AggregationConfig) Validate() error {
+	switch ac.Metric {
+	case AggregationMetricCount:
+	case AggregationMetricCardinality, AggregationMetricAvg, AggregationMetricSum, AggregationMetricMin, AggregationMetricMax:
+		if strings.TrimSpace(ac.Field) == "" {
+			return fmt.Errorf("field is required for %s", ac.Metric)
+		}
+	case AggregationMetricPercentiles:
+		if strings.TrimSpace(ac.Field) == "" {
+			return fmt.Errorf("field is required for %s", ac.Metric)
+		}
+		if ac.Percentile <= 0 || ac.Percentile >= 100 {
+			return fmt.Errorf("percentile must be between 0 and 100")
+		}
+	default:
+		return fmt.Errorf("invalid metric: %s", ac.Metric)
+	}
+	if ac.Size < 0 || ac.Size > maxAggregationBuckets {
+		return fmt.Errorf("size must be between 0 and %d", maxAggregationBuckets)
+	}
+	if ac.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	return ac.Operator.Validate()
+}
+
+// BucketSize returns the number of terms buckets to evaluate
+func (ac *AggregationConfig) BucketSize() int {
+	if ac.Size <= 0 {
+		return 10
+	}
+	return ac.Size
+}
+
+// PercentileKey returns the key ES uses for the configured percentile, e.g. "99.0"
+func (ac *AggregationConfig) PercentileKey() string {
+	key := strconv.FormatFloat(ac.Percentile, 'f', -1, 64)
+	if !strings.Contains(key, ".") {
+		key += ".0"
+	}
+	return key
+}
+
+// MetricLabel describes the metric, e.g. "count", "avg(bytes)" or "p99(upstream_response_time)"
+func (ac *AggregationConfig) MetricLabel() string {
+	switch ac.Metric {
+	case AggregationMetricCount:
+		return "count"
+	case AggregationMetricPercentiles:
+		return fmt.Sprintf("p%s(%s)", strconv.FormatFloat(ac.Percentile, 'f', -1, 64), ac.Field)
+	default:
+		return fmt.Sprintf("%s(%s)", ac.Metric, ac.Field)
+	}
+}
+
+// Holds reports whether a bucket metric satisfies the condition
+func (ac *AggregationConfig) Holds(value float64) bool {
+	return ac.Operator.Compare(value, ac.Threshold)
+}
+
+// AggregationBucket is one evaluated bucket of an aggregation rule
+type AggregationBucket struct {
+	Key      string  `json:"key"` // terms 桶的字段值；未分桶时为空
+	DocCount int64   `json:"doc_count"`
+	Value    float64 `json:"value"` // 指标值
+}
+
+// FormatMetricValue formats a metric value for display, rounded to 3 decimals
+func FormatMetricValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
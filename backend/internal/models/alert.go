@@ -17,8 +17,18 @@ import (
 type AlertStatus string
 
 const (
-	AlertStatusSent   AlertStatus = "sent"
-	AlertStatusFailed AlertStatus = "failed"
+	AlertStatusSent     AlertStatus = "sent"
+	AlertStatusFailed   AlertStatus = "failed"
+	AlertStatusSilenced AlertStatus = "silenced" // 命中静默规则，未发送通知
+)
+
+// AlertState represents the lifecycle state of an alert
+type AlertState string
+
+const (
+	AlertStateFiring       AlertState = "firing"       // 规则持续命中
+	AlertStateAcknowledged AlertState = "acknowledged" // 已被用户确认，继续命中时不再通知
+	AlertStateResolved     AlertState = "resolved"     // 连续 N 次未命中后恢复，或被手动恢复
 )
 
 // LogData stores the matched log data
@@ -55,6 +65,56 @@ func (ld *LogData) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, ld)
 }
 
+// AlertGroup is the matched logs of one group_by fingerprint within an alert
+type AlertGroup struct {
+	Fingerprint    string            `json:"fingerprint"`
+	Values         map[string]string `json:"values"` // group_by 字段值
+	Count          int               `json:"count"`  // 累计日志数
+	FirstSeen      time.Time         `json:"first_seen"`
+	LastSeen       time.Time         `json:"last_seen"`
+	LastNotifiedAt *time.Time        `json:"last_notified_at,omitempty"`
+}
+
+// AlertGroups is a slice of AlertGroup for JSON storage
+type AlertGroups []AlertGroup
+
+// Value implements driver.Valuer
+func (ag AlertGroups) Value() (driver.Value, error) {
+	if ag == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(ag)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (ag *AlertGroups) Scan(value interface{}) error {
+	if value == nil {
+		*ag = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*ag = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, ag)
+}
+
 // Alert represents an alert record
 type Alert struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -69,6 +129,27 @@ type Alert struct {
 	TimeRange string      `json:"time_range"` // e.g., "2025-11-28 10:00:00 ~ 10:01:00"
 	Status    AlertStatus `gorm:"default:'sent'" json:"status"`
 	ErrorMsg  string      `json:"error_msg,omitempty"`
+
+	// Lifecycle: consecutive matching executions fold into one alert until it resolves
+	State          AlertState `gorm:"default:'firing';index" json:"state"`
+	FireCount      int        `gorm:"default:1" json:"fire_count"`  // 合并的命中执行次数
+	CleanCount     int        `gorm:"default:0" json:"clean_count"` // 连续未命中执行次数
+	LastFiredAt    *time.Time `json:"last_fired_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+
+	// De-duplication: per-fingerprint counts when the rule has group_by fields
+	Groups         AlertGroups `gorm:"type:text" json:"groups,omitempty"`
+	LastNotifiedAt *time.Time  `json:"last_notified_at,omitempty"`
+
+	// Silence that muted the latest notification, if any
+	SilenceID *uint `gorm:"index" json:"silence_id,omitempty"`
+}
+
+// IsOpen reports whether the alert is still firing or acknowledged
+func (a *Alert) IsOpen() bool {
+	return a.State == AlertStateFiring || a.State == AlertStateAcknowledged
 }
 
 // TableName specifies the table name for Alert
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification channel types
+const (
+	ChannelTypeLark         = "lark"
+	ChannelTypeSlack        = "slack"
+	ChannelTypeDingTalk     = "dingtalk"
+	ChannelTypeWeCom        = "wecom"
+	ChannelTypeEmail        = "email"
+	ChannelTypeWebhook      = "webhook"
+	ChannelTypeAlertmanager = "alertmanager"
+	ChannelTypePagerDuty    = "pagerduty"
+	ChannelTypeOpsgenie     = "opsgenie"
+)
+
+// ChannelSettings holds type specific channel settings (webhook URL, tokens, ...)
+type ChannelSettings map[string]interface{}
+
+// Decode decodes settings into a typed settings struct
+func (cs ChannelSettings) Decode(v interface{}) error {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ChannelConfig represents a notification channel configuration
+type ChannelConfig struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name         string          `gorm:"not null;uniqueIndex" json:"name"`      // 配置名称
+	Type         string          `gorm:"not null;index" json:"type"`            // 渠道类型：lark, slack, dingtalk, wecom, email, webhook, alertmanager, pagerduty, opsgenie, ...
+	Settings     ChannelSettings `gorm:"-" json:"settings"`                     // 渠道配置（明文，仅内存中使用）
+	SettingsData string          `gorm:"column:settings;type:text" json:"-"`    // 渠道配置 JSON（可加密存储）
+	Description  string          `json:"description,omitempty"`                 // 描述
+	Enabled      bool            `gorm:"default:true" json:"enabled"`           // 是否启用
+	LastTestAt   *time.Time      `json:"last_test_at,omitempty"`                // 最后测试时间
+	TestStatus   string          `gorm:"default:unknown" json:"test_status"`    // 测试状态：unknown, success, failed
+	TestError    string          `gorm:"type:text" json:"test_error,omitempty"` // 测试错误信息
+
+	TemplateID *uint                 `gorm:"index" json:"template_id,omitempty"` // 通知模板 ID，规则未指定模板时使用
+	Template   *NotificationTemplate `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+}
+
+// TableName specifies the table name for ChannelConfig
+func (ChannelConfig) TableName() string {
+	return "channel_configs"
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GroupMatch decides how the children of a condition group combine
+type GroupMatch string
+
+const (
+	GroupMatchAll  GroupMatch = "all"  // 全部满足（AND）
+	GroupMatchAny  GroupMatch = "any"  // 至少满足一个（OR）
+	GroupMatchNone GroupMatch = "none" // 全部不满足（NOT）
+)
+
+// maxConditionDepth limits the nesting of condition groups
+const maxConditionDepth = 8
+
+// ConditionGroup is a node of the rule condition tree: child conditions and nested
+// groups combined by Match. The logic field of child conditions is ignored.
+type ConditionGroup struct {
+	Match      GroupMatch       `json:"match"` // all, any, none
+	Conditions []QueryCondition `json:"conditions,omitempty"`
+	Groups     []ConditionGroup `json:"groups,omitempty"`
+}
+
+// Value implements driver.Valuer
+func (g ConditionGroup) Value() (driver.Value, error) {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (g *ConditionGroup) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, g)
+}
+
+// Validate checks the match of every group, the fields of every condition and the depth of the tree
+func (g *ConditionGroup) Validate() error {
+	return g.validate("conditions", 1)
+}
+
+func (g *ConditionGroup) validate(path string, depth int) error {
+	if depth > maxConditionDepth {
+		return fmt.Errorf("%s: groups are nested deeper than %d levels", path, maxConditionDepth)
+	}
+	switch g.Match {
+	case GroupMatchAll, GroupMatchAny, GroupMatchNone:
+	default:
+		return fmt.Errorf("%s: invalid match: %s", path, g.Match)
+	}
+	for i, c := range g.Conditions {
+		if strings.TrimSpace(c.Field) == "" {
+			return fmt.Errorf("%s.conditions[%d]: field is required", path, i)
+		}
+	}
+	for i := range g.Groups {
+		if err := g.Groups[i].validate(fmt.Sprintf("%s.groups[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConditionsFromQueries converts a flat condition list into the equivalent tree:
+// conditions with logic "and" must all match, the others (logic "or" or unset)
+// form one group of which at least one must match. An empty list gives an empty
+// group, which matches every log in the time range.
+func ConditionsFromQueries(queries QueryConditions) *ConditionGroup {
+	root := &ConditionGroup{Match: GroupMatchAll}
+	anyGroup := ConditionGroup{Match: GroupMatchAny}
+	for _, q := range queries {
+		logic := q.Logic
+		q.Logic = ""
+		if logic == "and" {
+			root.Conditions = append(root.Conditions, q)
+		} else {
+			anyGroup.Conditions = append(anyGroup.Conditions, q)
+		}
+	}
+	if len(anyGroup.Conditions) > 0 {
+		root.Groups = append(root.Groups, anyGroup)
+	}
+	return root
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestConditionsFromQueriesMatchesMigration checks that ConditionsFromQueries builds the
+// tree migration 000016 writes for stored queries. migrated is the SQL result for the
+// same queries column: logic removed, jsonb_strip_nulls applied, empty parts omitted.
+func TestConditionsFromQueriesMatchesMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		queries  string
+		migrated string
+	}{
+		{
+			name:     "and only",
+			queries:  `[{"field":"level","value":"error","logic":"and"},{"field":"service","type":"term","value":"api","logic":"and"}]`,
+			migrated: `{"match":"all","conditions":[{"field":"level","value":"error"},{"field":"service","type":"term","value":"api"}]}`,
+		},
+		{
+			name:     "or only",
+			queries:  `[{"field":"status","operator":"equals","value":500,"logic":"or"},{"field":"status","operator":"equals","value":502,"logic":"or"}]`,
+			migrated: `{"match":"all","groups":[{"match":"any","conditions":[{"field":"status","operator":"equals","value":500},{"field":"status","operator":"equals","value":502}]}]}`,
+		},
+		{
+			name:     "unset logic counts as or",
+			queries:  `[{"field":"message","value":"timeout"},{"field":"message","value":"refused","logic":""}]`,
+			migrated: `{"match":"all","groups":[{"match":"any","conditions":[{"field":"message","value":"timeout"},{"field":"message","value":"refused"}]}]}`,
+		},
+		{
+			name:     "mixed keeps the order within each part",
+			queries:  `[{"field":"a","value":"1","logic":"or"},{"field":"b","value":"2","logic":"and"},{"field":"c","value":"3"},{"field":"d","op":"exists","value":null,"logic":"and"}]`,
+			migrated: `{"match":"all","conditions":[{"field":"b","value":"2"},{"field":"d","op":"exists"}],"groups":[{"match":"any","conditions":[{"field":"a","value":"1"},{"field":"c","value":"3"}]}]}`,
+		},
+		{
+			name:     "range value",
+			queries:  `[{"field":"bytes","type":"range","value":{"gte":1000},"logic":"and"}]`,
+			migrated: `{"match":"all","conditions":[{"field":"bytes","type":"range","value":{"gte":1000}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries QueryConditions
+			if err := queries.Scan(tt.queries); err != nil {
+				t.Fatalf("scan queries: %v", err)
+			}
+			var want ConditionGroup
+			if err := want.Scan(tt.migrated); err != nil {
+				t.Fatalf("scan migrated conditions: %v", err)
+			}
+
+			got := ConditionsFromQueries(queries)
+			if !reflect.DeepEqual(*got, want) {
+				gotJSON, _ := json.Marshal(got)
+				t.Errorf("ConditionsFromQueries = %s\nmigration gives     %s", gotJSON, tt.migrated)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("converted tree is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestConditionsFromQueriesEmpty(t *testing.T) {
+	for _, queries := range []QueryConditions{nil, {}} {
+		got := ConditionsFromQueries(queries)
+		if got.Match != GroupMatchAll || len(got.Conditions) != 0 || len(got.Groups) != 0 {
+			t.Errorf("ConditionsFromQueries(%v) = %+v, want an empty all group", queries, got)
+		}
+	}
+}
+
+func TestConditionGroupValidate(t *testing.T) {
+	deep := ConditionGroup{Match: GroupMatchAll}
+	for i := 0; i < maxConditionDepth; i++ {
+		deep = ConditionGroup{Match: GroupMatchAll, Groups: []ConditionGroup{deep}}
+	}
+
+	tests := []struct {
+		name    string
+		group   ConditionGroup
+		wantErr bool
+	}{
+		{name: "empty all group", group: ConditionGroup{Match: GroupMatchAll}},
+		{name: "none group", group: ConditionGroup{Match: GroupMatchNone, Conditions: []QueryCondition{{Field: "level", Value: "debug"}}}},
+		{name: "any nested in all", group: ConditionGroup{Match: GroupMatchAll, Groups: []ConditionGroup{{Match: GroupMatchAny, Conditions: []QueryCondition{{Field: "a"}, {Field: "b"}}}}}},
+		{name: "missing match", group: ConditionGroup{Conditions: []QueryCondition{{Field: "a"}}}, wantErr: true},
+		{name: "nested invalid match", group: ConditionGroup{Match: GroupMatchAll, Groups: []ConditionGroup{{Match: "xor"}}}, wantErr: true},
+		{name: "condition without field", group: ConditionGroup{Match: GroupMatchAny, Conditions: []QueryCondition{{Field: " "}}}, wantErr: true},
+		{name: "too deep", group: deep, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.group.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Display presets for notification cards
+const (
+	DisplayPresetAuto   = "auto"   // 按规则名称/日志字段自动选择 nginx 或 app 布局（兼容旧行为）
+	DisplayPresetNginx  = "nginx"  // nginx 访问日志布局
+	DisplayPresetApp    = "app"    // 应用日志布局
+	DisplayPresetCustom = "custom" // 使用规则的 display_fields
+)
+
+// Display field styles
+const (
+	DisplayStyleText  = "text"  // 普通文本
+	DisplayStyleCode  = "code"  // 行内代码
+	DisplayStyleBlock = "block" // 整行代码块
+)
+
+// Display field value formats
+const (
+	DisplayFormatTimestamp = "timestamp" // ISO 时间转为 2006-01-02 15:04:05
+	DisplayFormatPath      = "path"      // 去掉 URL 查询参数
+	DisplayFormatOneline   = "oneline"   // 换行替换为空格
+)
+
+// DisplayField describes one log field shown in notification cards
+type DisplayField struct {
+	Field     string `json:"field" yaml:"field"`                               // 字段路径，支持点号访问嵌套字段；多个备选字段用 "|" 分隔，取第一个非空值
+	Label     string `json:"label,omitempty" yaml:"label,omitempty"`           // 显示名称，默认为字段路径
+	MaxLength int    `json:"max_length,omitempty" yaml:"max_length,omitempty"` // 截断长度（字符数），0 表示不截断
+	Highlight string `json:"highlight,omitempty" yaml:"highlight,omitempty"`   // 高亮颜色：red, orange, yellow, green, blue, purple, grey 或 #RRGGBB
+	Style     string `json:"style,omitempty" yaml:"style,omitempty"`           // text, code, block
+	Format    string `json:"format,omitempty" yaml:"format,omitempty"`         // timestamp, path, oneline
+}
+
+// DisplayFields is a slice of DisplayField for JSON storage
+type DisplayFields []DisplayField
+
+// Value implements driver.Valuer
+func (df DisplayFields) Value() (driver.Value, error) {
+	if df == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(df)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (df *DisplayFields) Scan(value interface{}) error {
+	if value == nil {
+		*df = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*df = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, df)
+}
+
+// DisplayPresets are the built-in layouts offered for notification cards
+var DisplayPresets = map[string]DisplayFields{
+	DisplayPresetNginx: {
+		{Field: "response_code|status_code|status", Label: "状态码", Highlight: "red"},
+		{Field: "@timestamp", Label: "⏰ 时间", Format: DisplayFormatTimestamp},
+		{Field: "request|path", Label: "🔗 URL", Style: DisplayStyleCode, Format: DisplayFormatPath, MaxLength: 50},
+		{Field: "cf_ray", Label: "☁️ CF Ray", Style: DisplayStyleCode},
+		{Field: "domain", Label: "🌐 Domain", Style: DisplayStyleCode},
+	},
+	DisplayPresetApp: {
+		{Field: "module", Label: "📦 模块", Style: DisplayStyleCode},
+		{Field: "node_ip", Label: "🖥️ 节点", Style: DisplayStyleCode},
+		{Field: "@timestamp", Label: "⏰ 时间", Format: DisplayFormatTimestamp},
+		{Field: "message", Label: "💬 消息", Style: DisplayStyleBlock, Format: DisplayFormatOneline, MaxLength: 200},
+	},
+}
+
+// ResolveDisplayFields returns the display fields configured for the rule,
+// or nil when the layout should be detected automatically.
+func (r *Rule) ResolveDisplayFields() DisplayFields {
+	switch r.DisplayPreset {
+	case DisplayPresetCustom:
+		if len(r.DisplayFields) > 0 {
+			return r.DisplayFields
+		}
+		return nil
+	case DisplayPresetNginx, DisplayPresetApp:
+		return DisplayPresets[r.DisplayPreset]
+	default:
+		return nil
+	}
+}
+
+// SourceFields returns the log fields shown in notification cards together with the
+// rule's time fields, used as the _source filter of sampled logs
+func (r *Rule) SourceFields() []string {
+	fields := r.ResolveDisplayFields()
+	if fields == nil {
+		// auto 布局按日志字段在 nginx 与 app 之间选择，两种布局的字段都需要
+		fields = append(append(DisplayFields{}, DisplayPresets[DisplayPresetNginx]...), DisplayPresets[DisplayPresetApp]...)
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	add := func(field string) {
+		field = strings.TrimSpace(field)
+		if field != "" && !seen[field] {
+			seen[field] = true
+			result = append(result, field)
+		}
+	}
+	add(DefaultTimestampField)
+	add(r.WindowField())
+	for _, f := range fields {
+		for _, path := range strings.Split(f.Field, "|") {
+			add(path)
+		}
+	}
+	return result
+}
+
+// ValidateDisplay checks the display preset and custom display fields of a rule
+func (r *Rule) ValidateDisplay() error {
+	switch r.DisplayPreset {
+	case "", DisplayPresetAuto, DisplayPresetNginx, DisplayPresetApp:
+	case DisplayPresetCustom:
+		if len(r.DisplayFields) == 0 {
+			return fmt.Errorf("display_fields is required when display_preset is custom")
+		}
+	default:
+		return fmt.Errorf("invalid display_preset: %s", r.DisplayPreset)
+	}
+
+	for i, f := range r.DisplayFields {
+		if strings.TrimSpace(f.Field) == "" {
+			return fmt.Errorf("display_fields[%d]: field is required", i)
+		}
+		if f.MaxLength < 0 {
+			return fmt.Errorf("display_fields[%d]: max_length must not be negative", i)
+		}
+		switch f.Style {
+		case "", DisplayStyleText, DisplayStyleCode, DisplayStyleBlock:
+		default:
+			return fmt.Errorf("display_fields[%d]: invalid style: %s", i, f.Style)
+		}
+		switch f.Format {
+		case "", DisplayFormatTimestamp, DisplayFormatPath, DisplayFormatOneline:
+		default:
+			return fmt.Errorf("display_fields[%d]: invalid format: %s", i, f.Format)
+		}
+	}
+	return nil
+}
@@ -20,12 +20,16 @@ type LarkConfig struct {
 
 	Name        string     `gorm:"not null;uniqueIndex" json:"name"`         // 配置名称
 	WebhookURL  string     `gorm:"not null;type:text" json:"webhook_url"`    // Webhook URL
+	SignSecret  string     `gorm:"type:text" json:"-"`                       // 签名校验密钥（加密存储，不返回）
+	SignEnabled bool       `gorm:"-" json:"sign_enabled"`                    // 是否已配置签名密钥
 	IsDefault   bool       `gorm:"default:false" json:"is_default"`          // 是否为默认配置
 	Description string     `json:"description,omitempty"`                    // 描述
 	Enabled     bool       `gorm:"default:true" json:"enabled"`              // 是否启用
 	LastTestAt  *time.Time `json:"last_test_at,omitempty"`                   // 最后测试时间
 	TestStatus  string     `gorm:"default:unknown" json:"test_status"`       // 测试状态：unknown, success, failed
 	TestError   string     `gorm:"type:text" json:"test_error,omitempty"`    // 测试错误信息
+
+	ChannelConfigID *uint `gorm:"index" json:"channel_config_id,omitempty"` // 对应的 lark 通知渠道，告警通过该渠道发送
 }
 
 // TableName specifies the table name for LarkConfig
@@ -0,0 +1,32 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationTemplate customizes the title, header colour and body of alert notifications.
+// Title and Body are Go text/template strings rendered against the alert data.
+type NotificationTemplate struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name        string `gorm:"not null;uniqueIndex" json:"name"` // 模板名称
+	Title       string `gorm:"type:text" json:"title"`           // 标题模板，如 "🚨 {{.RuleName}}"
+	Color       string `json:"color,omitempty"`                  // 标题颜色：red, orange, yellow, green, blue, purple, grey 或 #RRGGBB
+	Body        string `gorm:"type:text" json:"body"`            // 正文模板（markdown）
+	Description string `json:"description,omitempty"`            // 描述
+}
+
+// TableName specifies the table name for NotificationTemplate
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// QueryMode decides how the query of a rule is written
+type QueryMode string
+
+const (
+	QueryModeConditions  QueryMode = "conditions"   // queries 条件列表（默认）
+	QueryModeDSL         QueryMode = "dsl"          // 原始 Elasticsearch Query DSL
+	QueryModeQueryString QueryMode = "query_string" // Lucene / KQL 查询字符串
+)
+
+// QueryLanguage is the syntax of a query string
+type QueryLanguage string
+
+const (
+	QueryLanguageLucene QueryLanguage = "lucene"
+	QueryLanguageKQL    QueryLanguage = "kql"
+)
+
+// QueryDSL is a raw Elasticsearch query object for JSON storage
+type QueryDSL map[string]interface{}
+
+// Value implements driver.Valuer
+func (q QueryDSL) Value() (driver.Value, error) {
+	if q == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (q *QueryDSL) Scan(value interface{}) error {
+	if value == nil {
+		*q = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*q = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, q)
+}
+
+// EffectiveQueryMode returns the query mode, defaulting to conditions
+func (r *Rule) EffectiveQueryMode() QueryMode {
+	if r.QueryMode == "" {
+		return QueryModeConditions
+	}
+	return r.QueryMode
+}
+
+// ValidateQueryMode checks that the query of the selected mode is present. The
+// query itself is validated against Elasticsearch when the rule is saved.
+func (r *Rule) ValidateQueryMode() error {
+	switch r.EffectiveQueryMode() {
+	case QueryModeConditions:
+		if r.Conditions != nil {
+			return r.Conditions.Validate()
+		}
+	case QueryModeDSL:
+		if len(r.QueryDSL) == 0 {
+			return fmt.Errorf("query_dsl is required for dsl query mode")
+		}
+		if len(r.QueryDSL) != 1 {
+			return fmt.Errorf("query_dsl must be a single query object, e.g. {\"bool\": {...}}")
+		}
+		if _, ok := r.QueryDSL["query"]; ok {
+			return fmt.Errorf("query_dsl must be the query object itself, without the surrounding \"query\" key")
+		}
+	case QueryModeQueryString:
+		if strings.TrimSpace(r.QueryString) == "" {
+			return fmt.Errorf("query_string is required for query_string query mode")
+		}
+		switch r.QueryLanguage {
+		case "", QueryLanguageLucene, QueryLanguageKQL:
+		default:
+			return fmt.Errorf("invalid query_language: %s", r.QueryLanguage)
+		}
+	default:
+		return fmt.Errorf("invalid query_mode: %s", r.QueryMode)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RatioConfig alerts when numerator / denominator satisfies "ratio operator threshold",
+// e.g. the share of 5xx responses among all requests > 0.05. Both counts are taken
+// over the same window and both are filtered by the rule's queries.
+type RatioConfig struct {
+	Numerator      QueryConditions `json:"numerator"`             // 分子的附加条件，如 status >= 500
+	Denominator    QueryConditions `json:"denominator,omitempty"` // 分母的附加条件；为空时分母为规则查询命中的全部日志
+	Operator       CompareOp       `json:"operator"`
+	Threshold      float64         `json:"threshold"`                 // 比例阈值，0.05 表示 5%
+	MinDenominator int64           `json:"min_denominator,omitempty"` // 分母低于该值时不评估，避免低流量下的噪声
+	Window         int             `json:"window,omitempty"`          // 回看窗口（秒），与执行间隔无关；为 0 时使用规则间隔
+}
+
+// Value implements driver.Valuer
+func (rc RatioConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(rc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (rc *RatioConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, rc)
+}
+
+// Validate checks the numerator, threshold and guard of a ratio
+func (rc *RatioConfig) Validate() error {
+	if len(rc.Numerator) == 0 {
+		return fmt.Errorf("numerator is required")
+	}
+	if rc.Threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if rc.MinDenominator < 0 {
+		return fmt.Errorf("min_denominator must not be negative")
+	}
+	if rc.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	return rc.Operator.Validate()
+}
+
+// Evaluate returns the ratio of the counts and whether the condition holds. The
+// condition never holds when the denominator is below the guard or zero.
+func (rc *RatioConfig) Evaluate(numerator, denominator int64) (float64, bool) {
+	if denominator <= 0 || denominator < rc.MinDenominator {
+		return 0, false
+	}
+	ratio := float64(numerator) / float64(denominator)
+	return ratio, rc.Operator.Compare(ratio, rc.Threshold)
+}
@@ -8,6 +8,8 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -57,6 +59,86 @@ func (qc *QueryConditions) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, qc)
 }
 
+// StringList is a slice of strings for JSON storage
+type StringList []string
+
+// Value implements driver.Valuer
+func (sl StringList) Value() (driver.Value, error) {
+	if sl == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(sl)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (sl *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*sl = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*sl = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, sl)
+}
+
+// Labels is a string map for JSON storage
+type Labels map[string]string
+
+// Value implements driver.Valuer
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
 // Rule represents an alert rule
 type Rule struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -69,20 +151,76 @@ type Rule struct {
 	Queries      QueryConditions `gorm:"type:text" json:"queries"`
 	Enabled      bool            `gorm:"default:true" json:"enabled"`
 	Interval     int             `gorm:"default:60" json:"interval"` // seconds
+	ResolveAfter int             `gorm:"default:1" json:"resolve_after"` // 连续 N 次执行未命中后告警自动恢复
 	ESConfigID   *uint           `gorm:"index" json:"es_config_id,omitempty"`           // ES 数据源配置 ID
 	ESConfig     *ESConfig       `gorm:"foreignKey:ESConfigID" json:"es_config,omitempty"` // ES 数据源配置关联
-	LarkWebhook  string          `json:"lark_webhook"`                // 保留用于向后兼容，如果设置了 LarkConfigID 则优先使用配置
-	LarkConfigID *uint           `gorm:"index" json:"lark_config_id,omitempty"` // Lark 配置 ID
+	LarkWebhook  string          `json:"lark_webhook"`                // 仅兼容旧接口：保存时转换为 lark 渠道，不再存储
+	LarkConfigID *uint           `gorm:"index" json:"lark_config_id,omitempty"` // 仅兼容旧接口：保存时关联该 Lark 配置对应的 lark 渠道
 	LarkConfig   *LarkConfig     `gorm:"foreignKey:LarkConfigID" json:"lark_config,omitempty"` // Lark 配置关联
+	ChannelIDs   []uint          `gorm:"-" json:"channel_ids,omitempty"`                       // 通知渠道 ID 列表（写入时使用）
+	Channels     []ChannelConfig `gorm:"many2many:rule_channels" json:"channels,omitempty"`    // 通知渠道关联，告警只通过这些渠道发送
 	Description  string          `json:"description,omitempty"`
+	Labels       Labels          `gorm:"type:text" json:"labels,omitempty"` // 规则标签，用于静默匹配；传 {} 清空
+
+	// Query mode: the condition tree, a raw DSL query object or a Lucene/KQL query string
+	Conditions    *ConditionGroup `gorm:"type:text" json:"conditions,omitempty"`          // 条件树，设置后优先于 queries；仅传 queries 时自动转换
+	QueryMode     QueryMode       `gorm:"default:conditions" json:"query_mode,omitempty"` // conditions, dsl, query_string
+	QueryDSL      QueryDSL        `gorm:"type:text" json:"query_dsl,omitempty"`           // query_mode 为 dsl 时的查询对象，如 {"bool": {...}}，不含时间范围
+	QueryString   string          `json:"query_string,omitempty"`                         // query_mode 为 query_string 时的查询字符串
+	QueryLanguage QueryLanguage   `json:"query_language,omitempty"`                       // lucene（默认）, kql
+
+	// Query window: timestamp field and ingest delay compensation
+	TimestampField  string     `json:"timestamp_field,omitempty"`                   // 事件时间字段，默认 @timestamp
+	WindowTime      WindowTime `gorm:"default:event" json:"window_time,omitempty"`  // event（事件时间）, ingest（按 event.ingested 写入时间）
+	EvaluationDelay *int       `gorm:"default:0" json:"evaluation_delay,omitempty"` // 评估延迟（秒），查询窗口整体后移，补偿日志写入延迟
+
+	// Rule type: match alerts on any matching log, the others evaluate a condition
+	Type        RuleType           `gorm:"default:match" json:"type,omitempty"`    // match, threshold, aggregation, ratio, absence
+	Threshold   *ThresholdConfig   `gorm:"type:text" json:"threshold,omitempty"`   // type 为 threshold 时使用
+	Aggregation *AggregationConfig `gorm:"type:text" json:"aggregation,omitempty"` // type 为 aggregation 时使用
+	Ratio       *RatioConfig       `gorm:"type:text" json:"ratio,omitempty"`       // type 为 ratio 时使用
+	Absence     *AbsenceConfig     `gorm:"type:text" json:"absence,omitempty"`     // type 为 absence 时使用
+
+	// Notification card layout
+	DisplayPreset string                `gorm:"default:auto" json:"display_preset,omitempty"` // auto, nginx, app, custom
+	DisplayFields DisplayFields         `gorm:"type:text" json:"display_fields,omitempty"`    // display_preset 为 custom 时使用
+	TemplateID    *uint                 `gorm:"index" json:"template_id,omitempty"`           // 通知模板 ID，优先于渠道模板
+	Template      *NotificationTemplate `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+
+	// Alert grouping and de-duplication
+	GroupBy          StringList `gorm:"type:text" json:"group_by,omitempty"`          // 分组字段，按字段值计算指纹；传 [] 清空
+	RenotifyInterval *int       `gorm:"default:0" json:"renotify_interval,omitempty"` // 同一分组重复通知的最小间隔（秒），0 表示每次命中都通知
+
+	// Active hours and maintenance windows
+	ScheduleIDs []uint     `gorm:"-" json:"schedule_ids,omitempty"`                      // 时间计划 ID 列表（写入时使用）
+	Schedules   []Schedule `gorm:"many2many:rule_schedules" json:"schedules,omitempty"` // 时间计划关联
 
 	// Statistics
-	LastRunTime *time.Time `json:"last_run_time,omitempty"`
-	RunCount    int64      `gorm:"default:0" json:"run_count"`
-	AlertCount  int64      `gorm:"default:0" json:"alert_count"`
+	LastRunTime   *time.Time `json:"last_run_time,omitempty"`
+	LastWindowEnd *time.Time `json:"last_window_end,omitempty"` // 上次评估的查询窗口终点（匹配规则从此处继续查询）
+	RunCount      int64      `gorm:"default:0" json:"run_count"`
+	AlertCount    int64      `gorm:"default:0" json:"alert_count"`
 }
 
 // TableName specifies the table name for Rule
 func (Rule) TableName() string {
 	return "rules"
 }
+
+// ValidateGrouping checks the group_by fields and re-notify interval of a rule
+func (r *Rule) ValidateGrouping() error {
+	seen := make(map[string]bool, len(r.GroupBy))
+	for i, f := range r.GroupBy {
+		if strings.TrimSpace(f) == "" {
+			return fmt.Errorf("group_by[%d]: field is required", i)
+		}
+		if seen[f] {
+			return fmt.Errorf("group_by[%d]: duplicate field %s", i, f)
+		}
+		seen[f] = true
+	}
+	if r.RenotifyInterval != nil && *r.RenotifyInterval < 0 {
+		return fmt.Errorf("renotify_interval must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RuleType decides how a rule's query result turns into an alert
+type RuleType string
+
+const (
+	RuleTypeMatch       RuleType = "match"       // 任意日志命中即告警（默认）
+	RuleTypeThreshold   RuleType = "threshold"   // 时间窗口内命中数满足阈值条件时告警
+	RuleTypeAggregation RuleType = "aggregation" // 聚合桶的指标满足条件时告警
+	RuleTypeRatio       RuleType = "ratio"       // 分子与分母查询命中数的比例满足条件时告警
+	RuleTypeAbsence     RuleType = "absence"     // 时间窗口内日志缺失（少于期望条数）时告警
+)
+
+// CompareOp is a comparison operator of rule conditions
+type CompareOp string
+
+const (
+	CompareGT  CompareOp = ">"
+	CompareGTE CompareOp = ">="
+	CompareLT  CompareOp = "<"
+	CompareLTE CompareOp = "<="
+	CompareEQ  CompareOp = "=="
+	CompareNE  CompareOp = "!="
+)
+
+// Validate checks that the operator is supported
+func (op CompareOp) Validate() error {
+	switch op {
+	case CompareGT, CompareGTE, CompareLT, CompareLTE, CompareEQ, CompareNE:
+		return nil
+	default:
+		return fmt.Errorf("invalid operator: %s", op)
+	}
+}
+
+// Compare reports whether "v op threshold" holds
+func (op CompareOp) Compare(v, threshold float64) bool {
+	switch op {
+	case CompareGT:
+		return v > threshold
+	case CompareGTE:
+		return v >= threshold
+	case CompareLT:
+		return v < threshold
+	case CompareLTE:
+		return v <= threshold
+	case CompareEQ:
+		return v == threshold
+	case CompareNE:
+		return v != threshold
+	default:
+		return false
+	}
+}
+
+// ThresholdConfig alerts when the number of matching logs in the lookback
+// window satisfies "matches operator count", e.g. "> 50" or "< 1"
+type ThresholdConfig struct {
+	Operator CompareOp `json:"operator"`
+	Count    int64     `json:"count"`
+	Window   int       `json:"window,omitempty"` // 回看窗口（秒），与执行间隔无关；为 0 时使用规则间隔
+}
+
+// Value implements driver.Valuer
+func (tc ThresholdConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(tc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (tc *ThresholdConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, tc)
+}
+
+// Validate checks the operator, count and window of a threshold
+func (tc *ThresholdConfig) Validate() error {
+	if err := tc.Operator.Validate(); err != nil {
+		return err
+	}
+	if tc.Count < 0 {
+		return fmt.Errorf("count must not be negative")
+	}
+	if tc.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	return nil
+}
+
+// Holds reports whether the match count satisfies the threshold
+func (tc *ThresholdConfig) Holds(count int64) bool {
+	return tc.Operator.Compare(float64(count), float64(tc.Count))
+}
+
+// AbsenceConfig alerts when fewer than MinCount matching logs arrived in the
+// lookback window, e.g. a service that stopped logging
+type AbsenceConfig struct {
+	Window   int   `json:"window,omitempty"`    // 回看窗口（秒），与执行间隔无关；为 0 时使用规则间隔
+	MinCount int64 `json:"min_count,omitempty"` // 窗口内期望的最少日志条数，默认 1
+}
+
+// Value implements driver.Valuer
+func (ac AbsenceConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(ac)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (ac *AbsenceConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, ac)
+}
+
+// Validate checks the window and expected count of an absence rule
+func (ac *AbsenceConfig) Validate() error {
+	if ac.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	if ac.MinCount < 0 {
+		return fmt.Errorf("min_count must not be negative")
+	}
+	return nil
+}
+
+// Expected returns the minimum number of logs expected in the window
+func (ac *AbsenceConfig) Expected() int64 {
+	if ac.MinCount <= 0 {
+		return 1
+	}
+	return ac.MinCount
+}
+
+// EffectiveType returns the rule type, defaulting to match
+func (r *Rule) EffectiveType() RuleType {
+	if r.Type == "" {
+		return RuleTypeMatch
+	}
+	return r.Type
+}
+
+// LookbackWindow returns the query window of the count-based rule types: the
+// configured window, or the rule interval when none is set
+func (r *Rule) LookbackWindow(window int) time.Duration {
+	if window > 0 {
+		return time.Duration(window) * time.Second
+	}
+	if r.Interval > 0 {
+		return time.Duration(r.Interval) * time.Second
+	}
+	return time.Minute
+}
+
+// ValidateType checks the rule type and its configuration
+func (r *Rule) ValidateType() error {
+	switch r.EffectiveType() {
+	case RuleTypeMatch:
+	case RuleTypeThreshold:
+		if r.Threshold == nil {
+			return fmt.Errorf("threshold is required for threshold rules")
+		}
+		if err := r.Threshold.Validate(); err != nil {
+			return fmt.Errorf("threshold: %w", err)
+		}
+	case RuleTypeAggregation:
+		if r.Aggregation == nil {
+			return fmt.Errorf("aggregation is required for aggregation rules")
+		}
+		if err := r.Aggregation.Validate(); err != nil {
+			return fmt.Errorf("aggregation: %w", err)
+		}
+	case RuleTypeRatio:
+		if r.Ratio == nil {
+			return fmt.Errorf("ratio is required for ratio rules")
+		}
+		if err := r.Ratio.Validate(); err != nil {
+			return fmt.Errorf("ratio: %w", err)
+		}
+	case RuleTypeAbsence:
+		// absence is optional: without it the rule expects at least one log per interval
+		if r.Absence != nil {
+			if err := r.Absence.Validate(); err != nil {
+				return fmt.Errorf("absence: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid type: %s", r.Type)
+	}
+	return nil
+}
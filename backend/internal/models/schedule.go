@@ -0,0 +1,239 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleKind decides what being inside a schedule window means for a rule
+type ScheduleKind string
+
+const (
+	ScheduleKindActive      ScheduleKind = "active"      // 生效时段：规则只在窗口内执行
+	ScheduleKindMaintenance ScheduleKind = "maintenance" // 维护窗口：规则在窗口内不执行
+)
+
+// weekdayNames maps the weekday names accepted in schedule windows
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ScheduleWindow is a weekly recurring time range. A window whose end is not
+// after its start crosses midnight and belongs to the weekday it starts on.
+type ScheduleWindow struct {
+	Weekdays []string `json:"weekdays,omitempty"` // mon, tue, wed, thu, fri, sat, sun；为空表示每天
+	Start    string   `json:"start"`              // HH:MM
+	End      string   `json:"end"`                // HH:MM，24:00 表示当天结束
+}
+
+// Validate checks the weekdays and the start/end times of a window
+func (w *ScheduleWindow) Validate() error {
+	for _, d := range w.Weekdays {
+		if _, ok := weekdayNames[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("invalid weekday: %s", d)
+		}
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	return nil
+}
+
+// contains reports whether the local time t falls inside the window
+func (w *ScheduleWindow) contains(t time.Time) bool {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return w.onDay(t.Weekday()) && minute >= start && minute < end
+	}
+	// Overnight window, e.g. 22:00-06:00
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.onDay(t.Weekday()) && minute >= start) || (w.onDay(yesterday) && minute < end)
+}
+
+func (w *ScheduleWindow) onDay(day time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if weekdayNames[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into minutes since midnight; "24:00" is allowed as an end
+func parseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	if h == 24 && m == 0 {
+		return 24 * 60, nil
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("%q is out of range", s)
+	}
+	return h*60 + m, nil
+}
+
+// ScheduleWindows is a slice of ScheduleWindow for JSON storage
+type ScheduleWindows []ScheduleWindow
+
+// Value implements driver.Valuer
+func (sw ScheduleWindows) Value() (driver.Value, error) {
+	if sw == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(sw)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (sw *ScheduleWindows) Scan(value interface{}) error {
+	if value == nil {
+		*sw = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*sw = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, sw)
+}
+
+// Schedule is a calendar of weekly windows in a timezone. Rules attached to an
+// active schedule only run inside its windows; rules attached to a maintenance
+// schedule do not run inside its windows.
+type Schedule struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name        string          `gorm:"not null;uniqueIndex" json:"name"`
+	Kind        ScheduleKind    `gorm:"not null" json:"kind"`              // active, maintenance
+	Timezone    string          `gorm:"default:UTC" json:"timezone"`       // IANA 时区，如 Asia/Shanghai
+	Windows     ScheduleWindows `gorm:"type:text;not null" json:"windows"` // 每周重复的时间窗口
+	Description string          `json:"description,omitempty"`
+}
+
+// TableName specifies the table name for Schedule
+func (Schedule) TableName() string {
+	return "schedules"
+}
+
+// Validate checks the kind, timezone and windows of a schedule
+func (s *Schedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch s.Kind {
+	case ScheduleKindActive, ScheduleKindMaintenance:
+	default:
+		return fmt.Errorf("invalid kind: %s", s.Kind)
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+	for i := range s.Windows {
+		if err := s.Windows[i].Validate(); err != nil {
+			return fmt.Errorf("windows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *Schedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Contains reports whether t falls inside any window of the schedule
+func (s *Schedule) Contains(t time.Time) bool {
+	loc, err := s.location()
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+	for i := range s.Windows {
+		if s.Windows[i].contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleAllows reports whether a rule may run at t under its schedules: inside
+// at least one active schedule (if it has any) and outside every maintenance schedule.
+// It also returns the schedules whose windows contain t.
+func (r *Rule) ScheduleAllows(t time.Time) (bool, []Schedule) {
+	var inside []Schedule
+	hasActive, inActive, inMaintenance := false, false, false
+	for _, s := range r.Schedules {
+		contains := s.Contains(t)
+		if contains {
+			inside = append(inside, s)
+		}
+		switch s.Kind {
+		case ScheduleKindActive:
+			hasActive = true
+			inActive = inActive || contains
+		case ScheduleKindMaintenance:
+			inMaintenance = inMaintenance || contains
+		}
+	}
+	return (!hasActive || inActive) && !inMaintenance, inside
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// at returns 2025-01-06 (a Monday) plus dayOffset days at hh:mm UTC
+func at(dayOffset, hh, mm int) time.Time {
+	return time.Date(2025, 1, 6+dayOffset, hh, mm, 0, 0, time.UTC)
+}
+
+func TestScheduleWindowContains(t *testing.T) {
+	tests := []struct {
+		name   string
+		window ScheduleWindow
+		t      time.Time
+		want   bool
+	}{
+		{name: "daytime inside", window: ScheduleWindow{Start: "09:00", End: "18:00"}, t: at(0, 12, 0), want: true},
+		{name: "daytime start is inclusive", window: ScheduleWindow{Start: "09:00", End: "18:00"}, t: at(0, 9, 0), want: true},
+		{name: "daytime end is exclusive", window: ScheduleWindow{Start: "09:00", End: "18:00"}, t: at(0, 18, 0), want: false},
+		{name: "daytime last minute", window: ScheduleWindow{Start: "09:00", End: "18:00"}, t: at(0, 17, 59), want: true},
+		{name: "daytime before start", window: ScheduleWindow{Start: "09:00", End: "18:00"}, t: at(0, 8, 59), want: false},
+		{name: "24:00 ends at midnight", window: ScheduleWindow{Start: "20:00", End: "24:00"}, t: at(0, 23, 59), want: true},
+		{name: "24:00 does not reach the next day", window: ScheduleWindow{Start: "20:00", End: "24:00"}, t: at(1, 0, 0), want: false},
+		{name: "weekday filter matches", window: ScheduleWindow{Weekdays: []string{"Mon", "tue"}, Start: "09:00", End: "18:00"}, t: at(1, 10, 0), want: true},
+		{name: "weekday filter excludes", window: ScheduleWindow{Weekdays: []string{"mon"}, Start: "09:00", End: "18:00"}, t: at(2, 10, 0), want: false},
+
+		{name: "overnight before midnight", window: ScheduleWindow{Start: "22:00", End: "06:00"}, t: at(0, 23, 30), want: true},
+		{name: "overnight after midnight", window: ScheduleWindow{Start: "22:00", End: "06:00"}, t: at(1, 5, 59), want: true},
+		{name: "overnight start is inclusive", window: ScheduleWindow{Start: "22:00", End: "06:00"}, t: at(0, 22, 0), want: true},
+		{name: "overnight end is exclusive", window: ScheduleWindow{Start: "22:00", End: "06:00"}, t: at(1, 6, 0), want: false},
+		{name: "overnight daytime gap", window: ScheduleWindow{Start: "22:00", End: "06:00"}, t: at(0, 12, 0), want: false},
+		{name: "overnight belongs to its start day", window: ScheduleWindow{Weekdays: []string{"fri"}, Start: "22:00", End: "06:00"}, t: at(5, 3, 0), want: true},
+		{name: "overnight morning of the start day", window: ScheduleWindow{Weekdays: []string{"fri"}, Start: "22:00", End: "06:00"}, t: at(4, 3, 0), want: false},
+		{name: "overnight sunday into monday", window: ScheduleWindow{Weekdays: []string{"sun"}, Start: "23:00", End: "01:00"}, t: at(0, 0, 30), want: true},
+		{name: "equal start and end spans the whole day", window: ScheduleWindow{Weekdays: []string{"mon"}, Start: "08:00", End: "08:00"}, t: at(1, 7, 59), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.contains(tt.t); got != tt.want {
+				t.Errorf("%s-%s %v contains %s = %v, want %v", tt.window.Start, tt.window.End, tt.window.Weekdays, tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleContainsUsesTimezone(t *testing.T) {
+	s := Schedule{Timezone: "Asia/Shanghai", Windows: ScheduleWindows{{Start: "09:00", End: "18:00"}}}
+
+	// 02:00 UTC is 10:00 in Shanghai
+	if !s.Contains(at(0, 2, 0)) {
+		t.Error("02:00 UTC should be inside 09:00-18:00 Asia/Shanghai")
+	}
+	// 12:00 UTC is 20:00 in Shanghai
+	if s.Contains(at(0, 12, 0)) {
+		t.Error("12:00 UTC should be outside 09:00-18:00 Asia/Shanghai")
+	}
+}
+
+func TestRuleScheduleAllows(t *testing.T) {
+	businessHours := Schedule{ID: 1, Kind: ScheduleKindActive, Windows: ScheduleWindows{{Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "18:00"}}}
+	nightShift := Schedule{ID: 2, Kind: ScheduleKindActive, Windows: ScheduleWindows{{Start: "22:00", End: "02:00"}}}
+	maintenance := Schedule{ID: 3, Kind: ScheduleKindMaintenance, Windows: ScheduleWindows{{Weekdays: []string{"mon"}, Start: "12:00", End: "13:00"}}}
+	overnightMaintenance := Schedule{ID: 4, Kind: ScheduleKindMaintenance, Windows: ScheduleWindows{{Start: "23:00", End: "01:00"}}}
+
+	tests := []struct {
+		name       string
+		schedules  []Schedule
+		t          time.Time
+		want       bool
+		wantInside []uint
+	}{
+		{name: "no schedules", t: at(0, 3, 0), want: true},
+		{name: "inside active", schedules: []Schedule{businessHours}, t: at(0, 10, 0), want: true, wantInside: []uint{1}},
+		{name: "outside active", schedules: []Schedule{businessHours}, t: at(5, 10, 0), want: false},
+		{name: "inside any of two active", schedules: []Schedule{businessHours, nightShift}, t: at(2, 1, 0), want: true, wantInside: []uint{2}},
+		{name: "maintenance only, outside", schedules: []Schedule{maintenance}, t: at(0, 11, 59), want: true},
+		{name: "maintenance only, inside", schedules: []Schedule{maintenance}, t: at(0, 12, 0), want: false, wantInside: []uint{3}},
+		{name: "maintenance overrides active", schedules: []Schedule{businessHours, maintenance}, t: at(0, 12, 30), want: false, wantInside: []uint{1, 3}},
+		{name: "active after maintenance ends", schedules: []Schedule{businessHours, maintenance}, t: at(0, 13, 0), want: true, wantInside: []uint{1}},
+		{name: "overnight maintenance overrides overnight active", schedules: []Schedule{nightShift, overnightMaintenance}, t: at(1, 0, 30), want: false, wantInside: []uint{2, 4}},
+		{name: "overnight active before maintenance", schedules: []Schedule{nightShift, overnightMaintenance}, t: at(0, 22, 30), want: true, wantInside: []uint{2}},
+		{name: "outside active and maintenance", schedules: []Schedule{businessHours, maintenance}, t: at(0, 20, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Schedules: tt.schedules}
+			got, inside := rule.ScheduleAllows(tt.t)
+			if got != tt.want {
+				t.Errorf("ScheduleAllows(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+			var insideIDs []uint
+			for _, s := range inside {
+				insideIDs = append(insideIDs, s.ID)
+			}
+			if len(insideIDs) != len(tt.wantInside) {
+				t.Fatalf("inside = %v, want %v", insideIDs, tt.wantInside)
+			}
+			for i := range insideIDs {
+				if insideIDs[i] != tt.wantInside[i] {
+					t.Fatalf("inside = %v, want %v", insideIDs, tt.wantInside)
+				}
+			}
+		})
+	}
+}
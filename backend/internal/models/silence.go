@@ -0,0 +1,206 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SilenceMatcherType is what a silence matcher compares
+type SilenceMatcherType string
+
+const (
+	SilenceMatchRuleName SilenceMatcherType = "rule_name" // 规则名称
+	SilenceMatchLabel    SilenceMatcherType = "label"     // 规则标签，Name 为标签键
+	SilenceMatchField    SilenceMatcherType = "field"     // 日志字段，Name 为字段路径
+)
+
+// SilenceState is the state of a silence at a point in time
+type SilenceState string
+
+const (
+	SilenceStatePending SilenceState = "pending"
+	SilenceStateActive  SilenceState = "active"
+	SilenceStateExpired SilenceState = "expired"
+)
+
+// SilenceMatcher matches a rule name, a rule label or a log field value
+type SilenceMatcher struct {
+	Type     SilenceMatcherType `json:"type"`
+	Name     string             `json:"name,omitempty"`     // 标签键或日志字段路径，rule_name 时为空
+	Operator string             `json:"operator,omitempty"` // =（默认）、!=、=~（正则）、!~
+	Value    string             `json:"value"`
+}
+
+// Validate checks the type, name and operator of a matcher
+func (m *SilenceMatcher) Validate() error {
+	switch m.Type {
+	case SilenceMatchRuleName:
+	case SilenceMatchLabel, SilenceMatchField:
+		if strings.TrimSpace(m.Name) == "" {
+			return fmt.Errorf("name is required for %s matchers", m.Type)
+		}
+	default:
+		return fmt.Errorf("invalid type: %s", m.Type)
+	}
+
+	switch m.Operator {
+	case "", "=", "!=":
+	case "=~", "!~":
+		if _, err := regexp.Compile(m.Value); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", m.Value, err)
+		}
+	default:
+		return fmt.Errorf("invalid operator: %s", m.Operator)
+	}
+	return nil
+}
+
+// Matches reports whether value satisfies the matcher. Regexes are anchored
+// like Alertmanager matchers, so "api-.*" must match the whole value.
+func (m *SilenceMatcher) Matches(value string) bool {
+	switch m.Operator {
+	case "!=":
+		return value != m.Value
+	case "=~", "!~":
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return false
+		}
+		return re.MatchString(value) == (m.Operator == "=~")
+	default:
+		return value == m.Value
+	}
+}
+
+// SilenceMatchers is a slice of SilenceMatcher for JSON storage
+type SilenceMatchers []SilenceMatcher
+
+// Value implements driver.Valuer
+func (sm SilenceMatchers) Value() (driver.Value, error) {
+	if sm == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(sm)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (sm *SilenceMatchers) Scan(value interface{}) error {
+	if value == nil {
+		*sm = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if len(bytes) == 0 || string(bytes) == "null" {
+		*sm = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, sm)
+}
+
+// Silence mutes the notifications of matching alerts between StartsAt and EndsAt.
+// Rules keep running and alerts are still recorded; all matchers must match.
+type Silence struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	StartsAt  time.Time       `gorm:"not null" json:"starts_at"`
+	EndsAt    time.Time       `gorm:"not null;index" json:"ends_at"`
+	CreatedBy string          `json:"created_by"`
+	Comment   string          `gorm:"type:text" json:"comment"`
+	Matchers  SilenceMatchers `gorm:"type:text;not null" json:"matchers"`
+
+	State SilenceState `gorm:"-" json:"state"` // 根据当前时间计算，不存储
+}
+
+// TableName specifies the table name for Silence
+func (Silence) TableName() string {
+	return "silences"
+}
+
+// Validate checks the time range and matchers of a silence
+func (s *Silence) Validate() error {
+	if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		return fmt.Errorf("starts_at and ends_at are required")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	for i := range s.Matchers {
+		if err := s.Matchers[i].Validate(); err != nil {
+			return fmt.Errorf("matchers[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// StateAt returns whether the silence is pending, active or expired at t
+func (s *Silence) StateAt(t time.Time) SilenceState {
+	switch {
+	case t.Before(s.StartsAt):
+		return SilenceStatePending
+	case t.Before(s.EndsAt):
+		return SilenceStateActive
+	default:
+		return SilenceStateExpired
+	}
+}
+
+// MatchesRule reports whether the rule name and label matchers match the rule
+func (s *Silence) MatchesRule(rule *Rule) bool {
+	for i := range s.Matchers {
+		m := &s.Matchers[i]
+		switch m.Type {
+		case SilenceMatchRuleName:
+			if !m.Matches(rule.Name) {
+				return false
+			}
+		case SilenceMatchLabel:
+			if !m.Matches(rule.Labels[m.Name]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FieldMatchers returns the log field matchers of the silence
+func (s *Silence) FieldMatchers() []SilenceMatcher {
+	var matchers []SilenceMatcher
+	for _, m := range s.Matchers {
+		if m.Type == SilenceMatchField {
+			matchers = append(matchers, m)
+		}
+	}
+	return matchers
+}
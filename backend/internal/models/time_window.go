@@ -0,0 +1,66 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WindowTime decides which timestamp the query window of a rule is applied to
+type WindowTime string
+
+const (
+	WindowTimeEvent  WindowTime = "event"  // 按事件时间（timestamp_field）划分窗口（默认）
+	WindowTimeIngest WindowTime = "ingest" // 按写入时间（event.ingested）划分窗口，迟到的日志不会漏查
+)
+
+const (
+	// DefaultTimestampField is the event time field used when a rule sets none
+	DefaultTimestampField = "@timestamp"
+	// IngestTimestampField is the ingest time field set by an ingest pipeline
+	IngestTimestampField = "event.ingested"
+
+	// maxEvaluationDelay caps the evaluation delay of a rule (seconds)
+	maxEvaluationDelay = 24 * 60 * 60
+)
+
+// WindowField returns the field the query window is applied to
+func (r *Rule) WindowField() string {
+	if r.WindowTime == WindowTimeIngest {
+		return IngestTimestampField
+	}
+	if r.TimestampField != "" {
+		return r.TimestampField
+	}
+	return DefaultTimestampField
+}
+
+// WindowEnd returns the end of the query window for an execution at now: the
+// window is shifted back by the evaluation delay so late logs are still queried
+func (r *Rule) WindowEnd(now time.Time) time.Time {
+	if r.EvaluationDelay == nil || *r.EvaluationDelay <= 0 {
+		return now
+	}
+	return now.Add(-time.Duration(*r.EvaluationDelay) * time.Second)
+}
+
+// ValidateTimeWindow checks the timestamp field, window time and evaluation delay of a rule
+func (r *Rule) ValidateTimeWindow() error {
+	if strings.ContainsAny(r.TimestampField, " \t\n") {
+		return fmt.Errorf("invalid timestamp_field: %q", r.TimestampField)
+	}
+	switch r.WindowTime {
+	case "", WindowTimeEvent, WindowTimeIngest:
+	default:
+		return fmt.Errorf("invalid window_time: %s", r.WindowTime)
+	}
+	if r.EvaluationDelay != nil && (*r.EvaluationDelay < 0 || *r.EvaluationDelay > maxEvaluationDelay) {
+		return fmt.Errorf("evaluation_delay must be between 0 and %d seconds", maxEvaluationDelay)
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/repository/database"
+	"gorm.io/gorm"
 )
 
 // Service provides alert management operations
@@ -33,8 +34,9 @@ func (s *Service) Create(alert *models.Alert) error {
 	return nil
 }
 
-// GetAll returns all alerts with pagination (without logs for performance)
-func (s *Service) GetAll(page, pageSize int) ([]models.Alert, int64, error) {
+// GetAll returns alerts with pagination (without logs for performance).
+// state filters by lifecycle state; "open" matches firing and acknowledged alerts.
+func (s *Service) GetAll(page, pageSize int, state string) ([]models.Alert, int64, error) {
 	var alerts []models.Alert
 	var total int64
 
@@ -43,15 +45,27 @@ func (s *Service) GetAll(page, pageSize int) ([]models.Alert, int64, error) {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if err := db.Model(&models.Alert{}).Count(&total).Error; err != nil {
+	filterState := func(tx *gorm.DB) *gorm.DB {
+		switch state {
+		case "":
+			return tx
+		case "open":
+			return tx.Where("state IN ?", []models.AlertState{models.AlertStateFiring, models.AlertStateAcknowledged})
+		default:
+			return tx.Where("state = ?", state)
+		}
+	}
+
+	if err := db.Model(&models.Alert{}).Scopes(filterState).Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count alerts: %w", err)
 	}
 
 	// Optimize: Don't load logs field in list view for performance
 	// Logs can be hundreds of KB or even MBs, causing slow page loads
 	// Only load logs when viewing individual alert details
-	if err := db.Preload("Rule").
-		Select("id", "created_at", "rule_id", "index_name", "log_count", "time_range", "status", "error_msg").
+	if err := db.Scopes(filterState).Preload("Rule").
+		Select("id", "created_at", "rule_id", "index_name", "log_count", "time_range", "status", "error_msg",
+			"state", "fire_count", "clean_count", "last_fired_at", "acknowledged_at", "acknowledged_by", "resolved_at", "last_notified_at", "silence_id").
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
@@ -95,6 +109,151 @@ func (s *Service) GetByRuleID(ruleID uint, limit int) ([]models.Alert, error) {
 	return alerts, nil
 }
 
+// GetOpenByRule returns the firing or acknowledged alert of a rule, or nil if there is none
+func (s *Service) GetOpenByRule(ruleID uint) (*models.Alert, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	var alerts []models.Alert
+	if err := db.Select("id", "created_at", "rule_id", "index_name", "log_count", "time_range", "status",
+		"state", "fire_count", "clean_count", "last_fired_at", "acknowledged_at", "acknowledged_by", "groups", "last_notified_at", "silence_id").
+		Where("rule_id = ? AND state IN ?", ruleID, []models.AlertState{models.AlertStateFiring, models.AlertStateAcknowledged}).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open alert: %w", err)
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	return &alerts[0], nil
+}
+
+// RecordFiring folds another matching execution into an open alert, together with
+// the merged per-group counts and the warning of an incomplete query result
+func (s *Service) RecordFiring(alert *models.Alert, logCount int, logs models.LogData, groups models.AlertGroups, timeRange string, firedAt time.Time, warning string) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Alert{}).Where("id = ?", alert.ID).Updates(map[string]interface{}{
+		"fire_count":    gorm.Expr("fire_count + 1"),
+		"clean_count":   0,
+		"log_count":     gorm.Expr("log_count + ?", logCount),
+		"logs":          logs,
+		"groups":        groups,
+		"time_range":    timeRange,
+		"last_fired_at": firedAt,
+		"error_msg":     warning,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update alert: %w", err)
+	}
+
+	alert.FireCount++
+	alert.CleanCount = 0
+	alert.LogCount += logCount
+	alert.Groups = groups
+	alert.TimeRange = timeRange
+	alert.LastFiredAt = &firedAt
+	alert.ErrorMsg = warning
+	return nil
+}
+
+// RecordNotified stores when an alert and its notified groups were last delivered
+// and marks the alert as sent; warning replaces any previous error message
+func (s *Service) RecordNotified(id uint, groups models.AlertGroups, notifiedAt time.Time, warning string) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Alert{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"groups":           groups,
+		"last_notified_at": notifiedAt,
+		"status":           models.AlertStatusSent,
+		"error_msg":        warning,
+		"silence_id":       nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update alert notified time: %w", err)
+	}
+	return nil
+}
+
+// RecordSilenced marks an alert whose notification was muted by a silence
+func (s *Service) RecordSilenced(id uint, silenceID uint, warning string) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Alert{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.AlertStatusSilenced,
+		"error_msg":  warning,
+		"silence_id": silenceID,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update alert silence: %w", err)
+	}
+	return nil
+}
+
+// UpdateCleanCount records the number of consecutive executions without matches
+func (s *Service) UpdateCleanCount(id uint, cleanCount int) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Alert{}).Where("id = ?", id).Update("clean_count", cleanCount).Error; err != nil {
+		return fmt.Errorf("failed to update alert clean count: %w", err)
+	}
+	return nil
+}
+
+// Acknowledge marks a firing alert as acknowledged. It returns false if the alert is not firing.
+func (s *Service) Acknowledge(id uint, by string) (bool, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	result := db.Model(&models.Alert{}).
+		Where("id = ? AND state = ?", id, models.AlertStateFiring).
+		Updates(map[string]interface{}{
+			"state":           models.AlertStateAcknowledged,
+			"acknowledged_at": &now,
+			"acknowledged_by": by,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acknowledge alert: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Resolve marks an open alert as resolved. It returns false if the alert was not open,
+// so concurrent callers send the resolved notification only once.
+func (s *Service) Resolve(id uint) (bool, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	result := db.Model(&models.Alert{}).
+		Where("id = ? AND state IN ?", id, []models.AlertState{models.AlertStateFiring, models.AlertStateAcknowledged}).
+		Updates(map[string]interface{}{
+			"state":       models.AlertStateResolved,
+			"resolved_at": &now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to resolve alert: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateDeliveryStatus records the notification result of an alert
+func (s *Service) UpdateDeliveryStatus(id uint, status models.AlertStatus, errorMsg string) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Alert{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":    status,
+		"error_msg": errorMsg,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update alert status: %w", err)
+	}
+	return nil
+}
+
 // Delete deletes an alert (hard delete - permanently removes from database)
 func (s *Service) Delete(id uint) error {
 	if err := database.DB.Unscoped().Delete(&models.Alert{}, id).Error; err != nil {
@@ -129,10 +288,26 @@ func (s *Service) GetStats(duration time.Duration) (map[string]interface{}, erro
 		return nil, err
 	}
 
+	// Open alerts are counted regardless of the duration
+	var firingCount, acknowledgedCount int64
+	if err := database.DB.Model(&models.Alert{}).
+		Where("state = ?", models.AlertStateFiring).
+		Count(&firingCount).Error; err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(&models.Alert{}).
+		Where("state = ?", models.AlertStateAcknowledged).
+		Count(&acknowledgedCount).Error; err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
-		"total":  totalCount,
-		"sent":   sentCount,
-		"failed": failedCount,
+		"total":        totalCount,
+		"sent":         sentCount,
+		"failed":       failedCount,
+		"firing":       firingCount,
+		"acknowledged": acknowledgedCount,
 	}, nil
 }
 
@@ -151,7 +326,8 @@ func (s *Service) BatchDelete(ids []uint) error {
 func (s *Service) CleanupOldData(olderThan time.Duration) (int64, error) {
 	cutoffTime := time.Now().Add(-olderThan)
 
-	result := database.DB.Unscoped().Where("created_at < ?", cutoffTime).Delete(&models.Alert{})
+	// Open alerts are kept until they resolve
+	result := database.DB.Unscoped().Where("created_at < ? AND state = ?", cutoffTime, models.AlertStateResolved).Delete(&models.Alert{})
 	if result.Error != nil {
 		return 0, fmt.Errorf("failed to cleanup old alerts: %w", result.Error)
 	}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package channel_config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appconfig "github.com/kk/elk-helper/backend/internal/config"
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/repository/database"
+	"github.com/kk/elk-helper/backend/internal/security"
+)
+
+// Service provides notification channel configuration management operations
+type Service struct{}
+
+// NewService creates a new channel config service
+func NewService() *Service {
+	return &Service{}
+}
+
+// GetAll returns all channel configurations
+func (s *Service) GetAll() ([]models.ChannelConfig, error) {
+	var configs []models.ChannelConfig
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Order("id ASC").Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get channel configs: %w", err)
+	}
+
+	for i := range configs {
+		if err := DecryptSettings(&configs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+// GetByID returns a channel config by ID
+func (s *Service) GetByID(id uint) (*models.ChannelConfig, error) {
+	var cfg models.ChannelConfig
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("channel config not found: %w", err)
+	}
+	if err := DecryptSettings(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetByName returns a channel config by name
+func (s *Service) GetByName(name string) (*models.ChannelConfig, error) {
+	var cfg models.ChannelConfig
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Where("name = ?", name).First(&cfg).Error; err != nil {
+		return nil, fmt.Errorf("channel config not found: %w", err)
+	}
+	if err := DecryptSettings(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Create creates a new channel configuration
+func (s *Service) Create(config *models.ChannelConfig) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := EncryptSettings(config); err != nil {
+		return err
+	}
+
+	fields := []string{"name", "type", "settings", "description", "enabled", "template_id"}
+	if err := db.Select(fields).Create(config).Error; err != nil {
+		return fmt.Errorf("failed to create channel config: %w", err)
+	}
+	return nil
+}
+
+// Update updates an existing channel configuration
+func (s *Service) Update(id uint, config *models.ChannelConfig) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := EncryptSettings(config); err != nil {
+		return err
+	}
+
+	updateData := map[string]interface{}{
+		"name":        config.Name,
+		"type":        config.Type,
+		"settings":    config.SettingsData,
+		"description": config.Description,
+		"enabled":     config.Enabled,
+		"template_id": config.TemplateID,
+	}
+
+	if err := db.Model(&models.ChannelConfig{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+		return fmt.Errorf("failed to update channel config: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a channel configuration (hard delete)
+func (s *Service) Delete(id uint) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	// Check if any rules are using this channel
+	var count int64
+	if err := db.Table("rule_channels").Where("channel_config_id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check rule usage: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot delete: %d rules are using this channel", count)
+	}
+
+	if err := db.Unscoped().Delete(&models.ChannelConfig{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete channel config: %w", err)
+	}
+	return nil
+}
+
+// UpdateTestResult updates the test result for a channel configuration
+func (s *Service) UpdateTestResult(id uint, status string, errMsg string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_test_at": &now,
+		"test_status":  status,
+		"test_error":   errMsg,
+	}
+
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.ChannelConfig{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update test result: %w", err)
+	}
+	return nil
+}
+
+// EncryptSettings serializes Settings into SettingsData, encrypting it when a key is configured
+func EncryptSettings(config *models.ChannelConfig) error {
+	settings := config.Settings
+	if settings == nil {
+		settings = models.ChannelSettings{}
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel settings: %w", err)
+	}
+	enc, err := security.MaybeEncrypt(string(raw), appconfig.AppConfig.Security.EncryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt channel settings: %w", err)
+	}
+	config.SettingsData = enc
+	return nil
+}
+
+// DecryptSettings restores Settings from the (possibly encrypted) SettingsData column
+func DecryptSettings(config *models.ChannelConfig) error {
+	if config.SettingsData == "" {
+		config.Settings = models.ChannelSettings{}
+		return nil
+	}
+	plain, err := security.MaybeDecrypt(config.SettingsData, appconfig.AppConfig.Security.EncryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt channel settings: %w", err)
+	}
+	var settings models.ChannelSettings
+	if err := json.Unmarshal([]byte(plain), &settings); err != nil {
+		return fmt.Errorf("failed to parse channel settings: %w", err)
+	}
+	config.Settings = settings
+	return nil
+}
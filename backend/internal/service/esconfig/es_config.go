@@ -14,6 +14,8 @@ import (
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/repository/database"
 	"github.com/kk/elk-helper/backend/internal/security"
+	"github.com/kk/elk-helper/backend/internal/service/query"
+	"gorm.io/gorm"
 )
 
 // Service provides ES configuration management operations
@@ -196,9 +198,22 @@ func (s *Service) Update(id uint, config *models.ESConfig) error {
 		updateData["ca_certificate"] = config.CACertificate
 	}
 
-	if err := db.Model(&models.ESConfig{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
-		return fmt.Errorf("failed to update ES config: %w", err)
+	// The updated_at trigger sets the stored timestamp; read it back so the registry
+	// tombstone matches the config rules will reload
+	var stored models.ESConfig
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ESConfig{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+			return fmt.Errorf("failed to update ES config: %w", err)
+		}
+		if err := tx.Select("id", "updated_at").First(&stored, id).Error; err != nil {
+			return fmt.Errorf("failed to reload ES config: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	query.InvalidateConfig(id, stored.UpdatedAt)
 	return nil
 }
 
@@ -210,6 +225,7 @@ func (s *Service) Delete(id uint) error {
 	if err := db.Unscoped().Delete(&models.ESConfig{}, id).Error; err != nil {
 		return fmt.Errorf("failed to delete ES config: %w", err)
 	}
+	query.InvalidateConfig(id, time.Now())
 	return nil
 }
 
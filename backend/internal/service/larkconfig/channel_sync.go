@@ -0,0 +1,275 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package lark_config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	appconfig "github.com/kk/elk-helper/backend/internal/config"
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/repository/database"
+	"github.com/kk/elk-helper/backend/internal/security"
+	channel_config "github.com/kk/elk-helper/backend/internal/service/channelconfig"
+	"gorm.io/gorm"
+)
+
+// Lark configs and rule webhooks are kept only as a compatibility input: each Lark
+// config is backed by a channel config of type lark, and alerts are delivered through
+// the rule's channels alone.
+
+// larkChannelSettings builds the settings of the lark channel backing a webhook
+func larkChannelSettings(webhookURL, signSecret string) models.ChannelSettings {
+	settings := models.ChannelSettings{"webhook_url": webhookURL}
+	if signSecret != "" {
+		settings["secret"] = signSecret
+	}
+	return settings
+}
+
+// uniqueChannelName returns base, or base with a suffix when a channel already uses the name
+func uniqueChannelName(tx *gorm.DB, base string) (string, error) {
+	name := base
+	for i := 1; ; i++ {
+		var count int64
+		if err := tx.Model(&models.ChannelConfig{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to check channel name: %w", err)
+		}
+		if count == 0 {
+			return name, nil
+		}
+		if i == 1 {
+			name = base + " (lark)"
+		} else {
+			name = fmt.Sprintf("%s (lark %d)", base, i)
+		}
+	}
+}
+
+// createLarkChannel creates a lark channel config with plaintext webhook settings
+func createLarkChannel(tx *gorm.DB, name, description, webhookURL, signSecret string, enabled bool) (*models.ChannelConfig, error) {
+	uniqueName, err := uniqueChannelName(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	channel := &models.ChannelConfig{
+		Name:        uniqueName,
+		Type:        models.ChannelTypeLark,
+		Settings:    larkChannelSettings(webhookURL, signSecret),
+		Description: description,
+		Enabled:     enabled,
+	}
+	if err := channel_config.EncryptSettings(channel); err != nil {
+		return nil, err
+	}
+	fields := []string{"name", "type", "settings", "description", "enabled"}
+	if err := tx.Select(fields).Create(channel).Error; err != nil {
+		return nil, fmt.Errorf("failed to create lark channel: %w", err)
+	}
+	return channel, nil
+}
+
+// syncChannel creates or updates the lark channel backing a (decrypted) Lark config
+func syncChannel(tx *gorm.DB, cfg *models.LarkConfig) error {
+	if cfg.ChannelConfigID == nil {
+		channel, err := createLarkChannel(tx, cfg.Name, cfg.Description, cfg.WebhookURL, cfg.SignSecret, cfg.Enabled)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.LarkConfig{}).Where("id = ?", cfg.ID).Update("channel_config_id", channel.ID).Error; err != nil {
+			return fmt.Errorf("failed to link Lark config to channel: %w", err)
+		}
+		cfg.ChannelConfigID = &channel.ID
+		return nil
+	}
+
+	channel := &models.ChannelConfig{Settings: larkChannelSettings(cfg.WebhookURL, cfg.SignSecret)}
+	if err := channel_config.EncryptSettings(channel); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.ChannelConfig{}).Where("id = ?", *cfg.ChannelConfigID).Updates(map[string]interface{}{
+		"settings":    channel.SettingsData,
+		"description": cfg.Description,
+		"enabled":     cfg.Enabled,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update lark channel: %w", err)
+	}
+	return nil
+}
+
+// SyncChannel updates the lark channel backing a Lark config after it was created or edited
+func (s *Service) SyncChannel(id uint) error {
+	cfg, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+	return syncChannel(db, cfg)
+}
+
+// ChannelForConfig returns the ID of the lark channel backing a Lark config
+func (s *Service) ChannelForConfig(id uint) (uint, error) {
+	cfg, err := s.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	if cfg.ChannelConfigID == nil {
+		if err := s.SyncChannel(id); err != nil {
+			return 0, err
+		}
+		if cfg, err = s.GetByID(id); err != nil {
+			return 0, err
+		}
+	}
+	return *cfg.ChannelConfigID, nil
+}
+
+// ChannelForWebhook returns the lark channel sending to a rule's direct webhook,
+// creating it when no unsigned lark channel uses the URL yet
+func (s *Service) ChannelForWebhook(ruleName, webhookURL string) (uint, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	channels, err := unsignedLarkChannels(db)
+	if err != nil {
+		return 0, err
+	}
+	return webhookChannel(db, channels, ruleName, webhookURL)
+}
+
+// unsignedLarkChannels maps webhook URLs to the unsigned lark channels sending to them
+func unsignedLarkChannels(tx *gorm.DB) (map[string]uint, error) {
+	var channels []models.ChannelConfig
+	if err := tx.Where("type = ?", models.ChannelTypeLark).Order("id").Find(&channels).Error; err != nil {
+		return nil, fmt.Errorf("failed to get lark channels: %w", err)
+	}
+	byURL := make(map[string]uint, len(channels))
+	for i := range channels {
+		if err := channel_config.DecryptSettings(&channels[i]); err != nil {
+			return nil, err
+		}
+		url, _ := channels[i].Settings["webhook_url"].(string)
+		secret, _ := channels[i].Settings["secret"].(string)
+		if _, ok := byURL[url]; !ok && secret == "" {
+			byURL[url] = channels[i].ID
+		}
+	}
+	return byURL, nil
+}
+
+// webhookChannel returns the channel in channels sending to webhookURL, or creates
+// one and adds it to channels
+func webhookChannel(tx *gorm.DB, channels map[string]uint, ruleName, webhookURL string) (uint, error) {
+	if id, ok := channels[webhookURL]; ok {
+		return id, nil
+	}
+	channel, err := createLarkChannel(tx, ruleName+" webhook", "迁移自规则 "+ruleName+" 的 lark_webhook", webhookURL, "", true)
+	if err != nil {
+		return 0, err
+	}
+	channels[webhookURL] = channel.ID
+	return channel.ID, nil
+}
+
+// legacyRule is a rule still carrying the Lark config or webhook it used to notify through
+type legacyRule struct {
+	ID           uint
+	Name         string
+	LarkConfigID *uint
+	LarkWebhook  string
+}
+
+// MigrateToChannels moves Lark configs and rule webhooks into lark channel configs
+// linked through rule_channels, then clears the rules' legacy fields. It runs at
+// startup because webhook URLs and secrets are encrypted with the application key;
+// migrated rows are skipped, so running it again is a no-op. Like the schema
+// migrations it runs without the query timeout, since its work grows with the rules.
+func (s *Service) MigrateToChannels() error {
+	db := database.DB.WithContext(context.Background())
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var configs []models.LarkConfig
+		if err := tx.Where("channel_config_id IS NULL").Find(&configs).Error; err != nil {
+			return fmt.Errorf("failed to get Lark configs: %w", err)
+		}
+		for i := range configs {
+			cfg := &configs[i]
+			plain, err := security.MaybeDecrypt(cfg.WebhookURL, appconfig.AppConfig.Security.EncryptionKey)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt webhook url of Lark config %d: %w", cfg.ID, err)
+			}
+			cfg.WebhookURL = plain
+			if err := DecryptSignSecret(cfg); err != nil {
+				return err
+			}
+			if err := syncChannel(tx, cfg); err != nil {
+				return err
+			}
+			slog.Info("Lark config migrated to channel", "lark_config_id", cfg.ID, "channel_config_id", *cfg.ChannelConfigID)
+		}
+
+		var rules []legacyRule
+		if err := tx.Model(&models.Rule{}).Select("id", "name", "lark_config_id", "lark_webhook").
+			Where("lark_config_id IS NOT NULL OR COALESCE(lark_webhook, '') <> ''").Find(&rules).Error; err != nil {
+			return fmt.Errorf("failed to get rules with Lark settings: %w", err)
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		channels, err := unsignedLarkChannels(tx)
+		if err != nil {
+			return err
+		}
+		for _, r := range rules {
+			// Rules with channels never used their Lark settings
+			var linked int64
+			if err := tx.Table("rule_channels").Where("rule_id = ?", r.ID).Count(&linked).Error; err != nil {
+				return fmt.Errorf("failed to check rule channels: %w", err)
+			}
+			if linked == 0 {
+				channelID, err := legacyRuleChannel(tx, channels, r)
+				if err != nil {
+					return err
+				}
+				if channelID != 0 {
+					if err := tx.Exec("INSERT INTO rule_channels (rule_id, channel_config_id) VALUES (?, ?)", r.ID, channelID).Error; err != nil {
+						return fmt.Errorf("failed to link rule %d to channel %d: %w", r.ID, channelID, err)
+					}
+					slog.Info("Rule Lark settings migrated to channel", "rule_id", r.ID, "channel_config_id", channelID)
+				}
+			}
+			if err := tx.Model(&models.Rule{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+				"lark_config_id": nil,
+				"lark_webhook":   "",
+			}).Error; err != nil {
+				return fmt.Errorf("failed to clear Lark settings of rule %d: %w", r.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// legacyRuleChannel returns the channel replacing a rule's Lark config or direct
+// webhook (the config took precedence), or 0 when neither is usable
+func legacyRuleChannel(tx *gorm.DB, channels map[string]uint, r legacyRule) (uint, error) {
+	if r.LarkConfigID != nil {
+		var cfg models.LarkConfig
+		err := tx.Select("id", "channel_config_id").First(&cfg, *r.LarkConfigID).Error
+		if err == nil && cfg.ChannelConfigID != nil {
+			return *cfg.ChannelConfigID, nil
+		}
+	}
+	if r.LarkWebhook == "" {
+		return 0, nil
+	}
+	plain, err := security.MaybeDecrypt(r.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt webhook of rule %d: %w", r.ID, err)
+	}
+	return webhookChannel(tx, channels, r.Name, plain)
+}
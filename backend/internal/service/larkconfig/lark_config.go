@@ -14,6 +14,7 @@ import (
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/repository/database"
 	"github.com/kk/elk-helper/backend/internal/security"
+	"gorm.io/gorm"
 )
 
 // Service provides Lark configuration management operations
@@ -40,6 +41,9 @@ func (s *Service) GetAll() ([]models.LarkConfig, error) {
 			return nil, fmt.Errorf("failed to decrypt webhook url: %w", err)
 		}
 		configs[i].WebhookURL = plain
+		if err := DecryptSignSecret(&configs[i]); err != nil {
+			return nil, err
+		}
 	}
 
 	return configs, nil
@@ -59,6 +63,9 @@ func (s *Service) GetByID(id uint) (*models.LarkConfig, error) {
 		return nil, fmt.Errorf("failed to decrypt webhook url: %w", err)
 	}
 	cfg.WebhookURL = plain
+	if err := DecryptSignSecret(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -79,6 +86,9 @@ func (s *Service) GetDefault() (*models.LarkConfig, error) {
 		return nil, fmt.Errorf("failed to decrypt webhook url: %w", err)
 	}
 	cfg.WebhookURL = plain
+	if err := DecryptSignSecret(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -96,6 +106,9 @@ func (s *Service) GetByName(name string) (*models.LarkConfig, error) {
 		return nil, fmt.Errorf("failed to decrypt webhook url: %w", err)
 	}
 	cfg.WebhookURL = plain
+	if err := DecryptSignSecret(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -109,6 +122,17 @@ func (s *Service) Create(config *models.LarkConfig) error {
 		return fmt.Errorf("failed to encrypt webhook url: %w", err)
 	}
 	config.WebhookURL = enc
+	// The backing lark channel is created below
+	config.ChannelConfigID = nil
+
+	plainSecret := config.SignSecret
+	if config.SignSecret != "" {
+		encSecret, err := security.MaybeEncrypt(config.SignSecret, appconfig.AppConfig.Security.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt sign secret: %w", err)
+		}
+		config.SignSecret = encSecret
+	}
 
 	// If this is set as default, unset other defaults
 	if config.IsDefault {
@@ -125,7 +149,9 @@ func (s *Service) Create(config *models.LarkConfig) error {
 	if err == nil {
 		config.WebhookURL = plain
 	}
-	return nil
+	config.SignSecret = plainSecret
+	config.SignEnabled = plainSecret != ""
+	return syncChannel(db, config)
 }
 
 // Update updates an existing Lark configuration
@@ -138,6 +164,9 @@ func (s *Service) Update(id uint, config *models.LarkConfig) error {
 		return fmt.Errorf("failed to encrypt webhook url: %w", err)
 	}
 	config.WebhookURL = enc
+	// Sign secret is updated explicitly via UpdateSignSecret
+	config.SignSecret = ""
+	config.ChannelConfigID = nil
 
 	// If this is set as default, unset other defaults
 	if config.IsDefault {
@@ -149,7 +178,26 @@ func (s *Service) Update(id uint, config *models.LarkConfig) error {
 	if err := db.Model(&models.LarkConfig{}).Where("id = ?", id).Updates(config).Error; err != nil {
 		return fmt.Errorf("failed to update Lark config: %w", err)
 	}
-	return nil
+	return s.SyncChannel(id)
+}
+
+// UpdateSignSecret sets (or clears, when empty) the signing secret of a Lark configuration
+func (s *Service) UpdateSignSecret(id uint, secret string) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if secret != "" {
+		enc, err := security.MaybeEncrypt(secret, appconfig.AppConfig.Security.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt sign secret: %w", err)
+		}
+		secret = enc
+	}
+
+	if err := db.Model(&models.LarkConfig{}).Where("id = ?", id).Update("sign_secret", secret).Error; err != nil {
+		return fmt.Errorf("failed to update sign secret: %w", err)
+	}
+	return s.SyncChannel(id)
 }
 
 // Delete deletes a Lark configuration
@@ -157,20 +205,34 @@ func (s *Service) Delete(id uint) error {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	// Check if any rules are using this config
+	var cfg models.LarkConfig
+	if err := db.Select("id", "channel_config_id").First(&cfg, id).Error; err != nil {
+		return fmt.Errorf("Lark config not found: %w", err)
+	}
+
+	// Check if any rules are using this config through its lark channel
 	var count int64
-	if err := db.Model(&models.Rule{}).Where("lark_config_id = ?", id).Count(&count).Error; err != nil {
-		return fmt.Errorf("failed to check rule usage: %w", err)
+	if cfg.ChannelConfigID != nil {
+		if err := db.Table("rule_channels").Where("channel_config_id = ?", *cfg.ChannelConfigID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check rule usage: %w", err)
+		}
 	}
 	if count > 0 {
 		return fmt.Errorf("cannot delete: %d rules are using this config", count)
 	}
 
 	// Hard delete - permanently removes from database to free disk space
-	if err := db.Unscoped().Delete(&models.LarkConfig{}, id).Error; err != nil {
-		return fmt.Errorf("failed to delete Lark config: %w", err)
-	}
-	return nil
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&models.LarkConfig{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete Lark config: %w", err)
+		}
+		if cfg.ChannelConfigID != nil {
+			if err := tx.Unscoped().Delete(&models.ChannelConfig{}, *cfg.ChannelConfigID).Error; err != nil {
+				return fmt.Errorf("failed to delete lark channel: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
 // UpdateTestResult updates the test result for a Lark configuration
@@ -210,3 +272,18 @@ func (s *Service) SetDefault(id uint) error {
 
 	return nil
 }
+
+// DecryptSignSecret decrypts the signing secret in place and sets SignEnabled
+func DecryptSignSecret(cfg *models.LarkConfig) error {
+	if cfg.SignSecret == "" {
+		cfg.SignEnabled = false
+		return nil
+	}
+	plain, err := security.MaybeDecrypt(cfg.SignSecret, appconfig.AppConfig.Security.EncryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sign secret: %w", err)
+	}
+	cfg.SignSecret = plain
+	cfg.SignEnabled = plain != ""
+	return nil
+}
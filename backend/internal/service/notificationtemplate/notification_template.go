@@ -0,0 +1,111 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notification_template
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/repository/database"
+)
+
+// Service provides notification template management operations
+type Service struct{}
+
+// NewService creates a new notification template service
+func NewService() *Service {
+	return &Service{}
+}
+
+// GetAll returns all notification templates
+func (s *Service) GetAll() ([]models.NotificationTemplate, error) {
+	var templates []models.NotificationTemplate
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Order("id ASC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetByID returns a notification template by ID
+func (s *Service) GetByID(id uint) (*models.NotificationTemplate, error) {
+	var tmpl models.NotificationTemplate
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.First(&tmpl, id).Error; err != nil {
+		return nil, fmt.Errorf("notification template not found: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// GetByName returns a notification template by name
+func (s *Service) GetByName(name string) (*models.NotificationTemplate, error) {
+	var tmpl models.NotificationTemplate
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Where("name = ?", name).First(&tmpl).Error; err != nil {
+		return nil, fmt.Errorf("notification template not found: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// Create creates a new notification template
+func (s *Service) Create(tmpl *models.NotificationTemplate) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Create(tmpl).Error; err != nil {
+		return fmt.Errorf("failed to create notification template: %w", err)
+	}
+	return nil
+}
+
+// Update updates an existing notification template
+func (s *Service) Update(id uint, tmpl *models.NotificationTemplate) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	updateData := map[string]interface{}{
+		"name":        tmpl.Name,
+		"title":       tmpl.Title,
+		"color":       tmpl.Color,
+		"body":        tmpl.Body,
+		"description": tmpl.Description,
+	}
+
+	if err := db.Model(&models.NotificationTemplate{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+		return fmt.Errorf("failed to update notification template: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a notification template (hard delete)
+func (s *Service) Delete(id uint) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	// Check if any rules or channels are using this template
+	var ruleCount, channelCount int64
+	if err := db.Model(&models.Rule{}).Where("template_id = ?", id).Count(&ruleCount).Error; err != nil {
+		return fmt.Errorf("failed to check rule usage: %w", err)
+	}
+	if err := db.Model(&models.ChannelConfig{}).Where("template_id = ?", id).Count(&channelCount).Error; err != nil {
+		return fmt.Errorf("failed to check channel usage: %w", err)
+	}
+	if ruleCount > 0 || channelCount > 0 {
+		return fmt.Errorf("cannot delete: %d rules and %d channels are using this template", ruleCount, channelCount)
+	}
+
+	if err := db.Unscoped().Delete(&models.NotificationTemplate{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete notification template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+const (
+	bucketsAggName = "buckets"
+	metricAggName  = "metric"
+)
+
+// AggregateLogs evaluates the aggregation of a rule between fromTime and toTime with a
+// single size 0 search and returns one bucket per terms value (or a single bucket
+// for the whole window when the aggregation has no group_by field). It fails with
+// ErrPartialResults when some shards failed.
+func (s *Service) AggregateLogs(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time) ([]models.AggregationBucket, error) {
+	agg := rule.Aggregation
+	if agg == nil {
+		return nil, fmt.Errorf("rule has no aggregation configured")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query := s.buildQuery(rule, fromTime, toTime, agg)
+	query["track_total_hits"] = true
+
+	searchResp, err := s.search(ctx, rule.IndexPattern, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	if partial := shardFailures(searchResp); partial != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPartialResults, partial)
+	}
+
+	buckets := parseAggregation(searchResp, agg)
+	slog.Info("Aggregation completed", "index_pattern", rule.IndexPattern, "metric", agg.MetricLabel(), "group_by", agg.GroupBy, "buckets", len(buckets))
+	return buckets, nil
+}
+
+// buildAggregations builds the aggs section of an aggregation rule. Terms buckets are
+// ordered by the metric so the buckets most likely to breach the condition come first.
+func buildAggregations(agg *models.AggregationConfig) map[string]interface{} {
+	metric := buildMetricAggregation(agg)
+	if agg.GroupBy == "" {
+		if metric == nil {
+			return nil // count of the whole window is hits.total
+		}
+		return map[string]interface{}{metricAggName: metric}
+	}
+
+	direction := "desc"
+	if agg.Operator == models.CompareLT || agg.Operator == models.CompareLTE {
+		direction = "asc"
+	}
+	orderKey := "_count"
+	switch agg.Metric {
+	case models.AggregationMetricCount:
+	case models.AggregationMetricPercentiles:
+		orderKey = fmt.Sprintf("%s[%s]", metricAggName, agg.PercentileKey())
+	default:
+		orderKey = metricAggName
+	}
+
+	terms := map[string]interface{}{
+		"terms": map[string]interface{}{
+			"field": agg.GroupBy,
+			"size":  agg.BucketSize(),
+			"order": map[string]interface{}{orderKey: direction},
+		},
+	}
+	if metric != nil {
+		terms["aggs"] = map[string]interface{}{metricAggName: metric}
+	}
+	return map[string]interface{}{bucketsAggName: terms}
+}
+
+// buildMetricAggregation builds the metric sub-aggregation, nil for count
+func buildMetricAggregation(agg *models.AggregationConfig) map[string]interface{} {
+	switch agg.Metric {
+	case models.AggregationMetricCount:
+		return nil
+	case models.AggregationMetricPercentiles:
+		return map[string]interface{}{
+			"percentiles": map[string]interface{}{
+				"field":    agg.Field,
+				"percents": []float64{agg.Percentile},
+			},
+		}
+	default:
+		return map[string]interface{}{
+			string(agg.Metric): map[string]interface{}{
+				"field": agg.Field,
+			},
+		}
+	}
+}
+
+// parseAggregation reads the evaluated buckets from a search response; buckets
+// without a metric value (e.g. avg of a field missing in every log) are skipped
+func parseAggregation(response map[string]interface{}, agg *models.AggregationConfig) []models.AggregationBucket {
+	aggs, _ := response["aggregations"].(map[string]interface{})
+
+	if agg.GroupBy == "" {
+		bucket := models.AggregationBucket{DocCount: totalHits(response)}
+		if agg.Metric == models.AggregationMetricCount {
+			bucket.Value = float64(bucket.DocCount)
+		} else {
+			value, ok := metricValue(aggs, agg)
+			if !ok {
+				return nil
+			}
+			bucket.Value = value
+		}
+		return []models.AggregationBucket{bucket}
+	}
+
+	terms, _ := aggs[bucketsAggName].(map[string]interface{})
+	rawBuckets, _ := terms["buckets"].([]interface{})
+	buckets := make([]models.AggregationBucket, 0, len(rawBuckets))
+	for _, raw := range rawBuckets {
+		b, _ := raw.(map[string]interface{})
+		docCount, _ := b["doc_count"].(float64)
+		bucket := models.AggregationBucket{Key: bucketKey(b), DocCount: int64(docCount)}
+		if agg.Metric == models.AggregationMetricCount {
+			bucket.Value = docCount
+		} else {
+			value, ok := metricValue(b, agg)
+			if !ok {
+				continue
+			}
+			bucket.Value = value
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// metricValue reads the metric sub-aggregation of a bucket (or of the top level)
+func metricValue(container map[string]interface{}, agg *models.AggregationConfig) (float64, bool) {
+	metric, _ := container[metricAggName].(map[string]interface{})
+	if agg.Metric == models.AggregationMetricPercentiles {
+		values, _ := metric["values"].(map[string]interface{})
+		for key, v := range values {
+			p, err := strconv.ParseFloat(key, 64)
+			if err != nil || p != agg.Percentile {
+				continue
+			}
+			value, ok := v.(float64)
+			return value, ok
+		}
+		return 0, false
+	}
+	value, ok := metric["value"].(float64)
+	return value, ok
+}
+
+// bucketKey returns the display key of a terms bucket
+func bucketKey(bucket map[string]interface{}) string {
+	if s, ok := bucket["key_as_string"].(string); ok {
+		return s
+	}
+	switch key := bucket["key"].(type) {
+	case string:
+		return key
+	case float64:
+		return strconv.FormatFloat(key, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", key)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/config"
+)
+
+var (
+	// ErrCircuitOpen is returned while the circuit breaker of a data source is open
+	ErrCircuitOpen = errors.New("ES data source circuit breaker is open")
+	// ErrSourceBusy is returned when a data source already runs its maximum number of queries
+	ErrSourceBusy = errors.New("ES data source concurrency limit reached")
+)
+
+// BreakerState is the state of a data source circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常查询
+	BreakerOpen     BreakerState = "open"      // 连续失败，冷却期内直接拒绝查询
+	BreakerHalfOpen BreakerState = "half_open" // 冷却结束，放行一次探测查询
+)
+
+// GuardStatus is the concurrency and circuit breaker state of a data source
+type GuardStatus struct {
+	State          BreakerState `json:"state"`
+	Failures       int          `json:"consecutive_failures"`
+	OpenedAt       *time.Time   `json:"opened_at,omitempty"`
+	LastError      string       `json:"last_error,omitempty"`
+	InFlight       int          `json:"in_flight"`
+	MaxConcurrency int          `json:"max_concurrency"`
+}
+
+// sourceGuard caps the concurrent queries of a data source and trips a circuit
+// breaker after consecutive failed requests, so a degraded cluster fails fast
+// instead of holding scheduler slots until its queries time out
+type sourceGuard struct {
+	slots       chan struct{}
+	maxFailures int
+	cooldown    time.Duration
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool // half-open probe in flight
+	lastError string
+}
+
+func newSourceGuard() *sourceGuard {
+	maxConcurrency, maxFailures, cooldown := 5, 5, time.Minute
+	if config.AppConfig != nil {
+		if config.AppConfig.ES.MaxConcurrency > 0 {
+			maxConcurrency = config.AppConfig.ES.MaxConcurrency
+		} else if config.AppConfig.Worker.MaxConcurrency > 1 {
+			// 默认最多占用一半的全局执行槽位，给其他数据源的规则留出余量
+			maxConcurrency = config.AppConfig.Worker.MaxConcurrency / 2
+		}
+		if config.AppConfig.ES.BreakerFailures > 0 {
+			maxFailures = config.AppConfig.ES.BreakerFailures
+		}
+		if config.AppConfig.ES.BreakerCooldownSeconds > 0 {
+			cooldown = time.Duration(config.AppConfig.ES.BreakerCooldownSeconds) * time.Second
+		}
+	}
+	return &sourceGuard{
+		slots:       make(chan struct{}, maxConcurrency),
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		state:       BreakerClosed,
+	}
+}
+
+// acquire admits one query operation. It fails fast when the breaker is open or all
+// slots are taken; the returned release must be called when the operation ends. The
+// returned context tags the requests of a half-open probe so only they decide the breaker.
+func (g *sourceGuard) acquire(ctx context.Context) (context.Context, func(), error) {
+	if g == nil {
+		return ctx, func() {}, nil
+	}
+	probe, err := g.allow()
+	if err != nil {
+		return ctx, nil, err
+	}
+	select {
+	case g.slots <- struct{}{}:
+	default:
+		if probe {
+			g.endProbe(nil)
+		}
+		return ctx, nil, ErrSourceBusy
+	}
+	if !probe {
+		return ctx, func() { <-g.slots }, nil
+	}
+
+	outcome := &probeOutcome{}
+	return context.WithValue(ctx, probeKey{}, outcome), func() {
+		<-g.slots
+		g.endProbe(outcome)
+	}, nil
+}
+
+// allow checks the breaker; after the cooldown a single caller is let through as the
+// probe, reported by the returned bool
+func (g *sourceGuard) allow() (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case BreakerOpen:
+		if time.Since(g.openedAt) < g.cooldown {
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, g.lastError)
+		}
+		g.state = BreakerHalfOpen
+	case BreakerHalfOpen:
+		if g.probing {
+			return false, fmt.Errorf("%w: probe in progress", ErrCircuitOpen)
+		}
+	default:
+		return false, nil
+	}
+	g.probing = true
+	return true, nil
+}
+
+// probeKey is the context key of the outcome of a half-open probe
+type probeKey struct{}
+
+// probeOutcome collects the outcome of the requests sent by a half-open probe
+type probeOutcome struct {
+	mu   sync.Mutex
+	sent bool
+	err  error // first failed request, nil when all succeeded
+}
+
+func (o *probeOutcome) add(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sent = true
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// endProbe ends the half-open probe and applies its outcome: the breaker closes when
+// every probe request succeeded and opens again on a failure. A probe that sent no
+// request (nil outcome, or it failed before reaching ES) lets the next caller probe.
+func (g *sourceGuard) endProbe(outcome *probeOutcome) {
+	var sent bool
+	var err error
+	if outcome != nil {
+		outcome.mu.Lock()
+		sent, err = outcome.sent, outcome.err
+		outcome.mu.Unlock()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.probing = false
+	if !sent || g.state != BreakerHalfOpen {
+		return
+	}
+	if err == nil {
+		g.state = BreakerClosed
+		g.failures = 0
+		return
+	}
+	g.failures++
+	g.lastError = err.Error()
+	g.state = BreakerOpen
+	g.openedAt = time.Now()
+}
+
+// record updates the breaker with the outcome of one ES request outside a probe.
+// While half-open only the probe decides, so late requests admitted before the
+// breaker opened are ignored.
+func (g *sourceGuard) record(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state != BreakerClosed {
+		return
+	}
+	if err == nil {
+		g.failures = 0
+		return
+	}
+
+	g.failures++
+	g.lastError = err.Error()
+	if g.failures >= g.maxFailures {
+		g.state = BreakerOpen
+		g.openedAt = time.Now()
+	}
+}
+
+// status returns a snapshot of the guard
+func (g *sourceGuard) status() GuardStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	st := GuardStatus{
+		State:          g.state,
+		Failures:       g.failures,
+		LastError:      g.lastError,
+		InFlight:       len(g.slots),
+		MaxConcurrency: cap(g.slots),
+	}
+	if g.state != BreakerClosed {
+		openedAt := g.openedAt
+		st.OpenedAt = &openedAt
+	}
+	return st
+}
+
+// guardedTransport reports the outcome of every ES request to the guard: transport
+// errors, 5xx and 429 responses count as failures, any other response as success
+type guardedTransport struct {
+	http.RoundTripper
+	guard *sourceGuard
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.RoundTripper.RoundTrip(req)
+	record := t.guard.record
+	if outcome, ok := req.Context().Value(probeKey{}).(*probeOutcome); ok {
+		record = outcome.add
+	}
+	switch {
+	case err != nil:
+		// Requests canceled by the caller (e.g. shutdown) say nothing about the cluster
+		if !errors.Is(err, context.Canceled) {
+			record(err)
+		}
+	case res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests:
+		record(fmt.Errorf("HTTP %d from %s", res.StatusCode, req.URL.Host))
+	default:
+		record(nil)
+	}
+	return res, err
+}
+
+// GuardStatusForConfig returns the guard state of an ES config's shared client; ok is
+// false when no rule has queried the data source since it was last changed
+func GuardStatusForConfig(id uint) (GuardStatus, bool) {
+	registry.Lock()
+	entry, ok := registry.entries[id]
+	registry.Unlock()
+	if !ok || entry.service == nil {
+		return GuardStatus{}, false
+	}
+	return entry.service.guard.status(), true
+}
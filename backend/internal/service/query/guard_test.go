@@ -0,0 +1,200 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// newTestGuard returns a closed guard with the given slots that trips after two failures
+func newTestGuard(slots int) *sourceGuard {
+	return &sourceGuard{
+		slots:       make(chan struct{}, slots),
+		maxFailures: 2,
+		cooldown:    time.Minute,
+		state:       BreakerClosed,
+	}
+}
+
+// tripAndCool opens the breaker with a cooldown that has already elapsed
+func tripAndCool(g *sourceGuard) {
+	g.state = BreakerOpen
+	g.failures = g.maxFailures
+	g.openedAt = time.Now().Add(-2 * g.cooldown)
+	g.lastError = "HTTP 503"
+}
+
+// sendThrough sends one request through a guarded transport answering with status,
+// or with a transport error when status is 0
+func sendThrough(t *testing.T, ctx context.Context, g *sourceGuard, status int) {
+	t.Helper()
+	transport := &guardedTransport{
+		RoundTripper: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if status == 0 {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+		}),
+		guard: g,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://es.local/_search", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if res, err := transport.RoundTrip(req); err == nil {
+		res.Body.Close()
+	}
+}
+
+func TestSourceGuardTripsAfterConsecutiveFailures(t *testing.T) {
+	g := newTestGuard(1)
+
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusTooManyRequests} {
+		sendThrough(t, context.Background(), g, status)
+	}
+	if g.state != BreakerClosed || g.failures != 1 {
+		t.Fatalf("after failure, success, failure: state %s failures %d, want closed with 1 failure", g.state, g.failures)
+	}
+
+	sendThrough(t, context.Background(), g, 0)
+	if g.state != BreakerOpen {
+		t.Fatalf("state = %s, want open", g.state)
+	}
+	if _, _, err := g.acquire(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("acquire during cooldown: err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestSourceGuardProbe(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // responses of the probe's requests, 0 for a transport error
+		wantState    BreakerState
+		wantFailures int
+	}{
+		{name: "successful probe closes", statuses: []int{http.StatusOK, http.StatusOK}, wantState: BreakerClosed, wantFailures: 0},
+		{name: "failed probe reopens", statuses: []int{http.StatusOK, http.StatusBadGateway}, wantState: BreakerOpen, wantFailures: 3},
+		{name: "transport error reopens", statuses: []int{0}, wantState: BreakerOpen, wantFailures: 3},
+		{name: "client error closes", statuses: []int{http.StatusBadRequest}, wantState: BreakerClosed, wantFailures: 0},
+		{name: "probe without request stays half-open", wantState: BreakerHalfOpen, wantFailures: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGuard(2)
+			tripAndCool(g)
+			reopenedAfter := time.Now()
+
+			ctx, release, err := g.acquire(context.Background())
+			if err != nil {
+				t.Fatalf("probe acquire: %v", err)
+			}
+			if g.state != BreakerHalfOpen || !g.probing {
+				t.Fatalf("state %s probing %v, want half-open probe", g.state, g.probing)
+			}
+			for _, status := range tt.statuses {
+				sendThrough(t, ctx, g, status)
+			}
+			if g.state != BreakerHalfOpen {
+				t.Fatalf("probe requests decided the breaker before release: state %s", g.state)
+			}
+			release()
+
+			if g.state != tt.wantState || g.failures != tt.wantFailures {
+				t.Errorf("state %s failures %d, want %s with %d failures", g.state, g.failures, tt.wantState, tt.wantFailures)
+			}
+			if g.probing {
+				t.Error("probe still marked in flight after release")
+			}
+			if tt.wantState == BreakerOpen && g.openedAt.Before(reopenedAfter) {
+				t.Error("reopened breaker kept the old openedAt, cooldown would not restart")
+			}
+			if len(g.slots) != 0 {
+				t.Errorf("%d slots still held after release", len(g.slots))
+			}
+		})
+	}
+}
+
+func TestSourceGuardProbeOwnership(t *testing.T) {
+	g := newTestGuard(3)
+	tripAndCool(g)
+
+	probeCtx, releaseProbe, err := g.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("probe acquire: %v", err)
+	}
+
+	// A second caller is refused while the probe is in flight
+	if _, _, err := g.acquire(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("acquire during probe: err = %v, want ErrCircuitOpen", err)
+	}
+
+	// Requests outside the probe, e.g. admitted before the breaker opened, do not decide it
+	sendThrough(t, context.Background(), g, http.StatusOK)
+	sendThrough(t, context.Background(), g, http.StatusServiceUnavailable)
+	if g.state != BreakerHalfOpen || !g.probing {
+		t.Fatalf("non-probe requests changed the breaker: state %s probing %v", g.state, g.probing)
+	}
+
+	sendThrough(t, probeCtx, g, http.StatusOK)
+	releaseProbe()
+	if g.state != BreakerClosed {
+		t.Fatalf("state = %s after successful probe, want closed", g.state)
+	}
+
+	// Once closed, callers are admitted without a probe context
+	ctx, release, err := g.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after close: %v", err)
+	}
+	if _, ok := ctx.Value(probeKey{}).(*probeOutcome); ok {
+		t.Error("caller admitted by a closed breaker was tagged as a probe")
+	}
+	release()
+}
+
+func TestSourceGuardBusy(t *testing.T) {
+	tests := []struct {
+		name    string
+		tripped bool
+	}{
+		{name: "closed"},
+		{name: "half-open probe", tripped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGuard(1)
+			g.slots <- struct{}{} // a query already holds the only slot
+			if tt.tripped {
+				tripAndCool(g)
+			}
+
+			if _, _, err := g.acquire(context.Background()); !errors.Is(err, ErrSourceBusy) {
+				t.Fatalf("acquire with full slots: err = %v, want ErrSourceBusy", err)
+			}
+			if g.probing {
+				t.Fatal("busy probe was not given up")
+			}
+
+			<-g.slots
+			_, release, err := g.acquire(context.Background())
+			if err != nil {
+				t.Fatalf("acquire after slot freed: %v", err)
+			}
+			release()
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// QueryResult is the outcome of querying the logs of a rule
+type QueryResult struct {
+	Logs      []map[string]interface{} // QueryLogs 为全部命中日志，SampleLogs 仅为样本
+	Total     int64                    // 窗口内命中总数
+	Truncated bool                     // 命中数超过 maxQueryResults，只返回了前 maxQueryResults 条
+	Partial   error                    // 部分分片失败或翻页中途出错；Logs 仍包含已取到的日志
+}
+
+// Warning describes an incomplete result, or returns "" when all matching logs were fetched
+func (r *QueryResult) Warning() string {
+	var parts []string
+	if r.Partial != nil {
+		parts = append(parts, fmt.Sprintf("partial results: %v", r.Partial))
+	}
+	if r.Truncated {
+		parts = append(parts, fmt.Sprintf("truncated: fetched %d of %d matching logs", len(r.Logs), r.Total))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// openPointInTime opens a point in time on the index pattern and returns its id
+func (s *Service) openPointInTime(ctx context.Context, indexPattern string) (string, error) {
+	req := esapi.OpenPointInTimeRequest{
+		Index:     []string{indexPattern},
+		KeepAlive: pitKeepAlive,
+	}
+
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		return "", fmt.Errorf("ES open point in time request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", fmt.Errorf("ES open point in time error: %s", res.String())
+	}
+
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", fmt.Errorf("error parsing point in time response: %w", err)
+	}
+	if pit.ID == "" {
+		return "", fmt.Errorf("ES open point in time returned no id")
+	}
+	return pit.ID, nil
+}
+
+// closePointInTime releases a point in time. It uses its own context so the PIT is
+// released even when the query context has expired.
+func (s *Service) closePointInTime(pitID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{"id": pitID})
+	if err != nil {
+		return
+	}
+	req := esapi.ClosePointInTimeRequest{Body: bytes.NewReader(body)}
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		slog.Warn("Failed to close point in time", "error", err)
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		slog.Warn("Failed to close point in time", "error", res.String())
+	}
+}
+
+// ErrPartialResults is returned by count and aggregation queries when some shards
+// failed: a partial count cannot tell whether a rule condition holds
+var ErrPartialResults = errors.New("ES returned partial results")
+
+// shardFailures returns an error when a search response is incomplete: some shards
+// failed or the search timed out
+func shardFailures(response map[string]interface{}) error {
+	if timedOut, _ := response["timed_out"].(bool); timedOut {
+		return fmt.Errorf("search timed out")
+	}
+
+	shards, _ := response["_shards"].(map[string]interface{})
+	failed, _ := shards["failed"].(float64)
+	if failed <= 0 {
+		return nil
+	}
+	total, _ := shards["total"].(float64)
+
+	var reasons []string
+	failures, _ := shards["failures"].([]interface{})
+	for _, f := range failures {
+		failure, _ := f.(map[string]interface{})
+		reason, _ := failure["reason"].(map[string]interface{})
+		if r, _ := reason["reason"].(string); r != "" {
+			reasons = append(reasons, r)
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("%d of %d shards failed", int(failed), int(total))
+	}
+	return fmt.Errorf("%d of %d shards failed: %s", int(failed), int(total), strings.Join(reasons, "; "))
+}
+
+// lastSortValues returns the sort values of the last hit, used as search_after of the next page
+func lastSortValues(response map[string]interface{}) []interface{} {
+	hits, _ := response["hits"].(map[string]interface{})
+	hitsList, _ := hits["hits"].([]interface{})
+	if len(hitsList) == 0 {
+		return nil
+	}
+	last, _ := hitsList[len(hitsList)-1].(map[string]interface{})
+	sortValues, _ := last["sort"].([]interface{})
+	return sortValues
+}
@@ -24,13 +24,15 @@ import (
 )
 
 const (
-	maxScrollResults = 10000
-	scrollTimeout    = 1 * time.Minute
+	maxQueryResults = 10000
+	pitKeepAlive    = "1m"
 )
 
 // Service provides Elasticsearch query operations
 type Service struct {
-	client *elasticsearch.Client
+	client    *elasticsearch.Client
+	transport *http.Transport
+	guard     *sourceGuard
 }
 
 // NewService creates a new query service using environment variables (backward compatibility)
@@ -72,14 +74,15 @@ func NewService() (*Service, error) {
 
 		transport.TLSClientConfig = tlsConfig
 	}
-	cfg.Transport = transport
+	guard := newSourceGuard()
+	cfg.Transport = &guardedTransport{RoundTripper: transport, guard: guard}
 
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ES client: %w", err)
 	}
 
-	return &Service{client: client}, nil
+	return &Service{client: client, transport: transport, guard: guard}, nil
 }
 
 // NewServiceFromConfig creates a new query service from ESConfig
@@ -142,14 +145,15 @@ func NewServiceFromConfig(esConfig *models.ESConfig) (*Service, error) {
 		transport.TLSClientConfig = tlsConfig
 	}
 
-	cfg.Transport = transport
+	guard := newSourceGuard()
+	cfg.Transport = &guardedTransport{RoundTripper: transport, guard: guard}
 
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ES client from config: %w", err)
 	}
 
-	return &Service{client: client}, nil
+	return &Service{client: client, transport: transport, guard: guard}, nil
 }
 
 // parseESAddresses parses semicolon-separated ES addresses
@@ -174,39 +178,169 @@ func parseESAddresses(urlString string) []string {
 	return addresses
 }
 
-// QueryLogs queries logs based on rule configuration
-func (s *Service) QueryLogs(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time, batchSize int) ([]map[string]interface{}, error) {
-	// Guardrail: enforce a maximum ES query duration to avoid occupying worker slots forever.
-	// If caller already has a deadline, keep the earlier one.
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		timeout := 30 * time.Second
-		if config.AppConfig != nil && config.AppConfig.ES.QueryTimeoutSeconds > 0 {
-			timeout = time.Duration(config.AppConfig.ES.QueryTimeoutSeconds) * time.Second
-		}
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, timeout)
-		defer cancel()
+// withQueryTimeout enforces a maximum ES query duration to avoid occupying worker slots forever.
+// If the caller already has a deadline, it is kept.
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	timeout := 30 * time.Second
+	if config.AppConfig != nil && config.AppConfig.ES.QueryTimeoutSeconds > 0 {
+		timeout = time.Duration(config.AppConfig.ES.QueryTimeoutSeconds) * time.Second
 	}
+	return context.WithTimeout(ctx, timeout)
+}
 
-	query := s.buildQuery(rule.Queries, fromTime, toTime)
+// QueryLogs pages through the logs matching a rule with a point in time and search_after,
+// up to maxQueryResults. Shard failures and errors after the first page are reported
+// in QueryResult.Partial together with the logs fetched so far.
+func (s *Service) QueryLogs(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time, batchSize int) (*QueryResult, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query := s.buildQuery(rule, fromTime, toTime, nil)
+	// _shard_doc is the cheapest unique tiebreaker within a point in time
+	query["sort"] = append(query["sort"].([]map[string]interface{}), map[string]interface{}{"_shard_doc": "asc"})
 
 	queryJSON, _ := json.MarshalIndent(query, "", "  ")
 	slog.Debug("Elasticsearch query", "query", string(queryJSON))
 
-	var results []map[string]interface{}
-	scrollID := ""
+	pitID, err := s.openPointInTime(ctx, rule.IndexPattern)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { s.closePointInTime(pitID) }()
+
+	result := &QueryResult{}
+	var searchAfter []interface{}
+	for len(result.Logs) < maxQueryResults {
+		size := batchSize
+		if remaining := maxQueryResults - len(result.Logs); size > remaining {
+			size = remaining
+		}
+
+		// Only the first page counts the total hits
+		query["pit"] = map[string]interface{}{"id": pitID, "keep_alive": pitKeepAlive}
+		query["track_total_hits"] = searchAfter == nil
+		if searchAfter != nil {
+			query["search_after"] = searchAfter
+		}
+
+		searchResp, err := s.search(ctx, "", query, size)
+		if err != nil {
+			if searchAfter == nil {
+				return nil, err
+			}
+			result.Partial = fmt.Errorf("page after %d logs failed: %w", len(result.Logs), err)
+			break
+		}
+		if id, _ := searchResp["pit_id"].(string); id != "" {
+			pitID = id
+		}
+		if searchAfter == nil {
+			result.Total = totalHits(searchResp)
+		}
+		if err := shardFailures(searchResp); err != nil && result.Partial == nil {
+			result.Partial = err
+		}
+
+		docs := s.extractDocuments(searchResp)
+		result.Logs = append(result.Logs, docs...)
+		slog.Debug("Search page completed", "page_docs", len(docs), "total_docs", len(result.Logs))
+
+		searchAfter = lastSortValues(searchResp)
+		if len(docs) < size || searchAfter == nil {
+			break
+		}
+	}
+	result.Truncated = len(result.Logs) >= maxQueryResults && result.Total > int64(len(result.Logs))
+
+	slog.Info("Query completed", "index_pattern", rule.IndexPattern, "total_hits", result.Total, "total_results", len(result.Logs), "truncated", result.Truncated, "partial", result.Partial != nil)
+	return result, nil
+}
+
+// SampleLogs counts the logs matching a rule with track_total_hits and fetches only the
+// first sampleSize of them, limited to sourceFields (nil fetches whole documents)
+func (s *Service) SampleLogs(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time, sampleSize int, sourceFields []string) (*QueryResult, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	// Initial search with scroll
+	query := s.buildQuery(rule, fromTime, toTime, nil)
+	query["track_total_hits"] = true
+	if len(sourceFields) > 0 {
+		query["_source"] = sourceFields
+	}
+
+	searchResp, err := s.search(ctx, rule.IndexPattern, query, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{
+		Logs:    s.extractDocuments(searchResp),
+		Total:   totalHits(searchResp),
+		Partial: shardFailures(searchResp),
+	}
+	slog.Info("Sample query completed", "index_pattern", rule.IndexPattern, "total_hits", result.Total, "samples", len(result.Logs), "partial", result.Partial != nil)
+	return result, nil
+}
+
+// CountLogs counts the logs matching a rule between fromTime and toTime with a single
+// track_total_hits search instead of paging through them, and returns up to sampleSize of them.
+// It fails with ErrPartialResults when some shards failed or the search timed out.
+func (s *Service) CountLogs(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time, sampleSize int) (int64, []map[string]interface{}, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+
+	query := s.buildQuery(rule, fromTime, toTime, nil)
+	query["track_total_hits"] = true
+
+	searchResp, err := s.search(ctx, rule.IndexPattern, query, sampleSize)
+	if err != nil {
+		return 0, nil, err
+	}
+	if partial := shardFailures(searchResp); partial != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrPartialResults, partial)
+	}
+
+	total := totalHits(searchResp)
+	samples := s.extractDocuments(searchResp)
+	slog.Info("Count completed", "index_pattern", rule.IndexPattern, "total", total, "samples", len(samples))
+	return total, samples, nil
+}
+
+// search runs a single search request and decodes the response. Point in time searches
+// pass an empty index pattern since the PIT already names the indices.
+func (s *Service) search(ctx context.Context, indexPattern string, query map[string]interface{}, size int) (map[string]interface{}, error) {
 	searchBody, err := json.Marshal(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
 	req := esapi.SearchRequest{
-		Index:  []string{rule.IndexPattern},
-		Body:   bytes.NewReader(searchBody),
-		Scroll: scrollTimeout,
-		Size:   &batchSize,
+		Body: bytes.NewReader(searchBody),
+		Size: &size,
+	}
+	if indexPattern != "" {
+		req.Index = []string{indexPattern}
 	}
 
 	res, err := req.Do(ctx, s.client)
@@ -227,58 +361,20 @@ func (s *Service) QueryLogs(ctx context.Context, rule *models.Rule, fromTime, to
 	if err := json.NewDecoder(res.Body).Decode(&searchResp); err != nil {
 		return nil, fmt.Errorf("error parsing response: %w", err)
 	}
+	return searchResp, nil
+}
 
-	scrollID, _ = searchResp["_scroll_id"].(string)
-	initialDocs := s.extractDocuments(searchResp)
-	results = append(results, initialDocs...)
-	slog.Info("Initial search completed", "index_pattern", rule.IndexPattern, "initial_docs", len(initialDocs), "total_docs", len(results))
-
-	// Continue scrolling
-	for scrollID != "" && len(results) < maxScrollResults {
-		scrollReq := esapi.ScrollRequest{
-			ScrollID: scrollID,
-			Scroll:   scrollTimeout,
-		}
-
-		scrollRes, err := scrollReq.Do(ctx, s.client)
-		if err != nil {
-			break
-		}
-
-		if scrollRes.IsError() {
-			break
-		}
-
-		var scrollResp map[string]interface{}
-		if err := json.NewDecoder(scrollRes.Body).Decode(&scrollResp); err != nil {
-			scrollRes.Body.Close()
-			break
-		}
-
-		hits, _ := scrollResp["hits"].(map[string]interface{})
-		hitsList, _ := hits["hits"].([]interface{})
-		if len(hitsList) == 0 {
-			scrollRes.Body.Close()
-			break
-		}
-
-		scrollDocs := s.extractDocuments(scrollResp)
-		results = append(results, scrollDocs...)
-		scrollID, _ = scrollResp["_scroll_id"].(string)
-		scrollRes.Body.Close()
-		slog.Debug("Scroll batch completed", "scroll_docs", len(scrollDocs), "total_docs", len(results))
-	}
-
-	// Clear scroll context
-	if scrollID != "" {
-		clearReq := esapi.ClearScrollRequest{
-			ScrollID: []string{scrollID},
-		}
-		_, _ = clearReq.Do(ctx, s.client)
-	}
-
-	slog.Info("Query completed", "index_pattern", rule.IndexPattern, "total_results", len(results))
-	return results, nil
+// totalHits reads hits.total of a search response ({"value": n} since ES 7, a number before)
+func totalHits(response map[string]interface{}) int64 {
+	hits, _ := response["hits"].(map[string]interface{})
+	switch total := hits["total"].(type) {
+	case map[string]interface{}:
+		value, _ := total["value"].(float64)
+		return int64(value)
+	case float64:
+		return int64(total)
+	}
+	return 0
 }
 
 // TestConnection tests ES connection
@@ -296,14 +392,15 @@ func (s *Service) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-// buildQuery builds ES query from rule queries
-func (s *Service) buildQuery(queries models.QueryConditions, fromTime, toTime time.Time) map[string]interface{} {
+// buildQuery builds ES query from the rule's query mode; with an aggregation it emits
+// the rule's aggregations instead of sorting the hits
+func (s *Service) buildQuery(rule *models.Rule, fromTime, toTime time.Time, agg *models.AggregationConfig) map[string]interface{} {
 	var mustClauses []map[string]interface{}
 
 	// Time range
 	mustClauses = append(mustClauses, map[string]interface{}{
 		"range": map[string]interface{}{
-			"@timestamp": map[string]interface{}{
+			rule.WindowField(): map[string]interface{}{
 				"gte":    fromTime.UTC().Format(time.RFC3339),
 				"lt":     toTime.UTC().Format(time.RFC3339),
 				"format": "strict_date_optional_time",
@@ -312,18 +409,27 @@ func (s *Service) buildQuery(queries models.QueryConditions, fromTime, toTime ti
 	})
 
 	// Process queries
-	queryClauses := s.buildFlexibleQueries(queries)
+	queryClauses := s.buildRuleQueries(rule)
 	mustClauses = append(mustClauses, queryClauses...)
 
-	return map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": mustClauses,
-			},
+	query := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": mustClauses,
 		},
+	}
+	if agg != nil {
+		body := map[string]interface{}{"query": query}
+		if aggs := buildAggregations(agg); len(aggs) > 0 {
+			body["aggs"] = aggs
+		}
+		return body
+	}
+
+	return map[string]interface{}{
+		"query": query,
 		"sort": []map[string]interface{}{
 			{
-				"@timestamp": map[string]interface{}{
+				rule.WindowField(): map[string]interface{}{
 					"order": "asc",
 				},
 			},
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+var (
+	kqlRangePattern = regexp.MustCompile(`([\w.@-]+)\s*(>=|<=|>|<)\s*`)
+	kqlColonPattern = regexp.MustCompile(`\s*:\s+|\s+:`)
+	kqlWordPattern  = regexp.MustCompile(`[^\s()]+`)
+)
+
+// buildRuleQueries builds the query clauses of a rule according to its query mode;
+// the time range is added by buildQuery in every mode
+func (s *Service) buildRuleQueries(rule *models.Rule) []map[string]interface{} {
+	switch rule.EffectiveQueryMode() {
+	case models.QueryModeDSL:
+		if len(rule.QueryDSL) == 0 {
+			return nil
+		}
+		return []map[string]interface{}{map[string]interface{}(rule.QueryDSL)}
+	case models.QueryModeQueryString:
+		query := rule.QueryString
+		if rule.QueryLanguage == models.QueryLanguageKQL {
+			query = kqlToLucene(query)
+		}
+		return []map[string]interface{}{
+			{
+				"query_string": map[string]interface{}{
+					"query":            query,
+					"analyze_wildcard": true,
+				},
+			},
+		}
+	default:
+		if rule.Conditions != nil {
+			if clause := s.buildConditionGroup(rule.Conditions); clause != nil {
+				return []map[string]interface{}{clause}
+			}
+			return nil
+		}
+		return s.buildFlexibleQueries(rule.Queries)
+	}
+}
+
+// buildConditionGroup compiles a condition group into a nested bool query; empty
+// groups (or groups whose conditions are all unsupported) compile to nil
+func (s *Service) buildConditionGroup(group *models.ConditionGroup) map[string]interface{} {
+	var clauses []map[string]interface{}
+	for _, c := range group.Conditions {
+		if clause := s.buildSingleQuery(c); clause != nil {
+			clauses = append(clauses, clause)
+		}
+	}
+	for i := range group.Groups {
+		if clause := s.buildConditionGroup(&group.Groups[i]); clause != nil {
+			clauses = append(clauses, clause)
+		}
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	switch group.Match {
+	case models.GroupMatchAny:
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               clauses,
+				"minimum_should_match": 1,
+			},
+		}
+	case models.GroupMatchNone:
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": clauses,
+			},
+		}
+	default:
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": clauses,
+			},
+		}
+	}
+}
+
+// kqlToLucene rewrites the common KQL syntax into Lucene query_string syntax:
+// lowercase and/or/not become operators, "field: value" loses the space and
+// "field >= 10" becomes "field:>=10". Quoted phrases, including escaped quotes
+// inside them, are kept as they are.
+func kqlToLucene(kql string) string {
+	var out strings.Builder
+	rewrite := func(part string) {
+		part = kqlRangePattern.ReplaceAllString(part, "$1:$2")
+		part = kqlColonPattern.ReplaceAllString(part, ":")
+		out.WriteString(kqlWordPattern.ReplaceAllStringFunc(part, func(word string) string {
+			switch strings.ToLower(word) {
+			case "and", "or", "not":
+				return strings.ToUpper(word)
+			}
+			return word
+		}))
+	}
+
+	start, quoted := 0, false
+	for i := 0; i < len(kql); i++ {
+		switch {
+		case kql[i] == '\\' && quoted:
+			i++ // skip the escaped character
+		case kql[i] == '"' && quoted:
+			out.WriteString(kql[start : i+1])
+			start, quoted = i+1, false
+		case kql[i] == '"':
+			rewrite(kql[start:i])
+			start, quoted = i, true
+		}
+	}
+	if quoted {
+		// Unterminated phrase: keep it as written and let ES report the syntax error
+		out.WriteString(kql[start:])
+	} else {
+		rewrite(kql[start:])
+	}
+	return out.String()
+}
+
+// ValidateQuery validates the query of a rule with the ES _validate/query API
+func (s *Service) ValidateQuery(ctx context.Context, rule *models.Rule) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	now := time.Now()
+	query := s.buildQuery(rule, now.Add(-time.Minute), now, nil)
+	body, err := json.Marshal(map[string]interface{}{"query": query["query"]})
+	if err != nil {
+		return fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	explain, allowNoIndices := true, true
+	req := esapi.IndicesValidateQueryRequest{
+		Index:          []string{rule.IndexPattern},
+		Body:           bytes.NewReader(body),
+		Explain:        &explain,
+		AllowNoIndices: &allowNoIndices,
+	}
+
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		return fmt.Errorf("ES validate request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Valid        bool   `json:"valid"`
+		Error        string `json:"error"`
+		Explanations []struct {
+			Valid bool   `json:"valid"`
+			Error string `json:"error"`
+		} `json:"explanations"`
+	}
+	if res.IsError() {
+		var e map[string]interface{}
+		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+			return fmt.Errorf("error parsing error response: %w", err)
+		}
+		return fmt.Errorf("ES validate error: %v", e)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+
+	if !result.Valid {
+		for _, e := range result.Explanations {
+			if !e.Valid && e.Error != "" {
+				return fmt.Errorf("invalid query: %s", e.Error)
+			}
+		}
+		if result.Error != "" {
+			return fmt.Errorf("invalid query: %s", result.Error)
+		}
+		return fmt.Errorf("invalid query")
+	}
+	return nil
+}
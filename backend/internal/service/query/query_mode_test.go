@@ -0,0 +1,185 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+func TestKQLToLucene(t *testing.T) {
+	tests := []struct {
+		name string
+		kql  string
+		want string
+	}{
+		{name: "field value", kql: "level: error", want: "level:error"},
+		{name: "already lucene", kql: "level:error AND NOT status:200", want: "level:error AND NOT status:200"},
+		{name: "space before colon", kql: "level : error", want: "level:error"},
+		{name: "boolean operators", kql: "level: error and service: api or not host: web-1", want: "level:error AND service:api OR NOT host:web-1"},
+		{name: "mixed case operators", kql: "a: 1 And b: 2 oR c: 3", want: "a:1 AND b:2 OR c:3"},
+		{name: "operator words inside values are kept", kql: "host: android and user: notify", want: "host:android AND user:notify"},
+
+		{name: "quoted value", kql: `message: "connection refused"`, want: `message:"connection refused"`},
+		{name: "operators inside quotes are kept", kql: `message: "not found or gone" and level: error`, want: `message:"not found or gone" AND level:error`},
+		{name: "colon inside quotes is kept", kql: `url: "http: //example"`, want: `url:"http: //example"`},
+		{name: "escaped quote inside phrase", kql: `message: "say \"not\" here" and level: warn`, want: `message:"say \"not\" here" AND level:warn`},
+		{name: "range inside quotes is kept", kql: `message: "a > b"`, want: `message:"a > b"`},
+		{name: "unterminated phrase is kept", kql: `message: "not closed and`, want: `message:"not closed and`},
+
+		{name: "not before group", kql: "not (level: debug or level: info)", want: "NOT (level:debug OR level:info)"},
+		{name: "operators inside nested groups", kql: "(a: 1 and (b: 2 or not c: 3)) or d: 4", want: "(a:1 AND (b:2 OR NOT c:3)) OR d:4"},
+		{name: "field group", kql: "status: (500 or 502 or 503)", want: "status:(500 OR 502 OR 503)"},
+		{name: "field group without space", kql: "status:(404 or not 410)", want: "status:(404 OR NOT 410)"},
+		{name: "quoted values in field group", kql: `service: ("order api" or "pay api")`, want: `service:("order api" OR "pay api")`},
+
+		{name: "range greater or equal", kql: "bytes >= 1000", want: "bytes:>=1000"},
+		{name: "range without spaces", kql: "bytes<500", want: "bytes:<500"},
+		{name: "range on dotted field", kql: "http.response.status_code > 499 and url.path: /api", want: "http.response.status_code:>499 AND url.path:/api"},
+		{name: "range on @timestamp", kql: `@timestamp <= "2025-01-01T00:00:00Z"`, want: `@timestamp:<="2025-01-01T00:00:00Z"`},
+		{name: "range inside group", kql: "not (duration > 5 or duration <= 0)", want: "NOT (duration:>5 OR duration:<=0)"},
+
+		{name: "wildcard", kql: "host.name: web-*", want: "host.name:web-*"},
+		{name: "empty", kql: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kqlToLucene(tt.kql); got != tt.want {
+				t.Errorf("kqlToLucene(%q) = %q, want %q", tt.kql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildConditionGroup(t *testing.T) {
+	s := &Service{}
+	level := models.QueryCondition{Field: "level", Type: "term", Value: "error"}
+	debug := models.QueryCondition{Field: "level", Type: "term", Value: "debug"}
+	status := models.QueryCondition{Field: "status", Type: "term", Value: 500}
+	levelClause := map[string]interface{}{"term": map[string]interface{}{"level": "error"}}
+	debugClause := map[string]interface{}{"term": map[string]interface{}{"level": "debug"}}
+	statusClause := map[string]interface{}{"term": map[string]interface{}{"status": 500}}
+
+	tests := []struct {
+		name  string
+		group models.ConditionGroup
+		want  map[string]interface{}
+	}{
+		{name: "empty group matches all", group: models.ConditionGroup{Match: models.GroupMatchAll}},
+		{name: "empty any group matches all", group: models.ConditionGroup{Match: models.GroupMatchAny}},
+		{
+			name:  "only empty subgroups match all",
+			group: models.ConditionGroup{Match: models.GroupMatchAll, Groups: []models.ConditionGroup{{Match: models.GroupMatchAny}, {Match: models.GroupMatchNone}}},
+		},
+		{
+			name:  "unsupported conditions are skipped",
+			group: models.ConditionGroup{Match: models.GroupMatchAll, Conditions: []models.QueryCondition{{Field: "bytes", Type: "range", Value: "not a map"}}},
+		},
+		{
+			name:  "all",
+			group: models.ConditionGroup{Match: models.GroupMatchAll, Conditions: []models.QueryCondition{level, status}},
+			want:  map[string]interface{}{"bool": map[string]interface{}{"must": []map[string]interface{}{levelClause, statusClause}}},
+		},
+		{
+			name:  "none",
+			group: models.ConditionGroup{Match: models.GroupMatchNone, Conditions: []models.QueryCondition{debug, status}},
+			want:  map[string]interface{}{"bool": map[string]interface{}{"must_not": []map[string]interface{}{debugClause, statusClause}}},
+		},
+		{
+			name: "any nested in all",
+			group: models.ConditionGroup{
+				Match:      models.GroupMatchAll,
+				Conditions: []models.QueryCondition{status},
+				Groups:     []models.ConditionGroup{{Match: models.GroupMatchAny, Conditions: []models.QueryCondition{level, debug}}},
+			},
+			want: map[string]interface{}{"bool": map[string]interface{}{"must": []map[string]interface{}{
+				statusClause,
+				{"bool": map[string]interface{}{"should": []map[string]interface{}{levelClause, debugClause}, "minimum_should_match": 1}},
+			}}},
+		},
+		{
+			name: "none nested in any, empty sibling skipped",
+			group: models.ConditionGroup{
+				Match:  models.GroupMatchAny,
+				Groups: []models.ConditionGroup{{Match: models.GroupMatchAll}, {Match: models.GroupMatchNone, Conditions: []models.QueryCondition{debug}}},
+			},
+			want: map[string]interface{}{"bool": map[string]interface{}{
+				"should":               []map[string]interface{}{{"bool": map[string]interface{}{"must_not": []map[string]interface{}{debugClause}}}},
+				"minimum_should_match": 1,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.buildConditionGroup(&tt.group)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("buildConditionGroup = %s, want %s", gotJSON, wantJSON)
+			}
+
+			// A tree compiling to nothing adds no clause: the rule matches every log in the window
+			rule := &models.Rule{Conditions: &tt.group}
+			if clauses := s.buildRuleQueries(rule); (tt.want == nil) != (len(clauses) == 0) {
+				t.Errorf("buildRuleQueries returned %d clauses for tree %s", len(clauses), tt.name)
+			}
+		})
+	}
+}
+
+// TestConvertedFlatRuleQueries checks that a flat rule converted with
+// ConditionsFromQueries queries the same logs as the flat list did
+func TestConvertedFlatRuleQueries(t *testing.T) {
+	s := &Service{}
+	tests := []struct {
+		name    string
+		queries models.QueryConditions
+	}{
+		{name: "empty"},
+		{name: "and only", queries: models.QueryConditions{
+			{Field: "level", Value: "error", Logic: "and"},
+			{Field: "service", Type: "term", Value: "api", Logic: "and"},
+		}},
+		{name: "or only", queries: models.QueryConditions{
+			{Field: "status", Operator: "equals", Value: 500},
+			{Field: "status", Operator: "equals", Value: 502, Logic: "or"},
+		}},
+		{name: "mixed", queries: models.QueryConditions{
+			{Field: "message", Value: "timeout", Logic: "or"},
+			{Field: "env", Type: "term", Value: "prod", Logic: "and"},
+			{Field: "message", Op: "contains", Value: "refused"},
+			{Field: "trace.id", Type: "exists", Logic: "and"},
+		}},
+		{name: "unsupported or condition", queries: models.QueryConditions{
+			{Field: "env", Type: "term", Value: "prod", Logic: "and"},
+			{Field: "bytes", Type: "range", Value: "not a map"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flat := s.buildRuleQueries(&models.Rule{Queries: tt.queries})
+			tree := s.buildRuleQueries(&models.Rule{Queries: tt.queries, Conditions: models.ConditionsFromQueries(tt.queries)})
+
+			// The flat clauses are must clauses of the time range query, the tree's root
+			// all group wraps the same clauses in one bool.must
+			var want []map[string]interface{}
+			if len(flat) > 0 {
+				want = []map[string]interface{}{{"bool": map[string]interface{}{"must": flat}}}
+			}
+			if !reflect.DeepEqual(tree, want) {
+				treeJSON, _ := json.Marshal(tree)
+				wantJSON, _ := json.Marshal(want)
+				t.Errorf("converted tree = %s, want %s", treeJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// CountRatio counts the numerator and denominator queries of a ratio rule over the
+// same window with one track_total_hits search each, and returns up to sampleSize
+// numerator logs. It fails with ErrPartialResults when either search is incomplete.
+func (s *Service) CountRatio(ctx context.Context, rule *models.Rule, fromTime, toTime time.Time, sampleSize int) (numerator, denominator int64, samples []map[string]interface{}, err error) {
+	ratio := rule.Ratio
+	if ratio == nil {
+		return 0, 0, nil, fmt.Errorf("rule has no ratio configured")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx, release, err := s.guard.acquire(ctx)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer release()
+
+	denominatorResp, err := s.search(ctx, rule.IndexPattern, s.buildCountQuery(rule, ratio.Denominator, fromTime, toTime), 0)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("denominator query failed: %w", err)
+	}
+	if partial := shardFailures(denominatorResp); partial != nil {
+		return 0, 0, nil, fmt.Errorf("denominator query: %w: %v", ErrPartialResults, partial)
+	}
+	denominator = totalHits(denominatorResp)
+
+	numeratorResp, err := s.search(ctx, rule.IndexPattern, s.buildCountQuery(rule, ratio.Numerator, fromTime, toTime), sampleSize)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("numerator query failed: %w", err)
+	}
+	if partial := shardFailures(numeratorResp); partial != nil {
+		return 0, 0, nil, fmt.Errorf("numerator query: %w: %v", ErrPartialResults, partial)
+	}
+	numerator = totalHits(numeratorResp)
+	samples = s.extractDocuments(numeratorResp)
+
+	slog.Info("Ratio counted", "index_pattern", rule.IndexPattern, "numerator", numerator, "denominator", denominator)
+	return numerator, denominator, samples, nil
+}
+
+// buildCountQuery builds a track_total_hits query matching both the rule query and
+// the extra conditions; each keeps its own and/or logic
+func (s *Service) buildCountQuery(rule *models.Rule, extra models.QueryConditions, fromTime, toTime time.Time) map[string]interface{} {
+	query := s.buildQuery(rule, fromTime, toTime, nil)
+	query["track_total_hits"] = true
+
+	if clauses := s.buildFlexibleQueries(extra); len(clauses) > 0 {
+		boolQuery := query["query"].(map[string]interface{})["bool"].(map[string]interface{})
+		boolQuery["must"] = append(boolQuery["must"].([]map[string]interface{}), clauses...)
+	}
+	return query
+}
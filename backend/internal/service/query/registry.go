@@ -0,0 +1,92 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package query
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// registryEntry is a cached query service built from one version of an ES config, or
+// a tombstone (nil service) left by InvalidateConfig
+type registryEntry struct {
+	updatedAt time.Time
+	service   *Service
+}
+
+// registry shares query services, and with them their connection pools, across rule
+// executions. Entries are keyed by ES config ID and rebuilt when the config's
+// UpdatedAt moves forward. After an invalidation, configs older than the tombstone
+// (rules loaded before the edit) are refused so they cannot cache a stale client.
+var registry = struct {
+	sync.Mutex
+	entries map[uint]*registryEntry
+}{entries: make(map[uint]*registryEntry)}
+
+// ServiceForConfig returns the shared query service of an ES config, building it on
+// first use or after the config was edited
+func ServiceForConfig(esConfig *models.ESConfig) (*Service, error) {
+	if esConfig == nil {
+		return nil, fmt.Errorf("ES config is nil")
+	}
+	if !esConfig.Enabled {
+		return nil, fmt.Errorf("ES config is disabled")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	// A rule loaded before the config was edited carries an older UpdatedAt; keep the newer client
+	entry, ok := registry.entries[esConfig.ID]
+	if ok && entry.service == nil && esConfig.UpdatedAt.Before(entry.updatedAt) {
+		return nil, fmt.Errorf("ES config %d changed since the rule was loaded", esConfig.ID)
+	}
+	if ok && entry.service != nil && !esConfig.UpdatedAt.After(entry.updatedAt) {
+		return entry.service, nil
+	}
+
+	service, err := NewServiceFromConfig(esConfig)
+	if err != nil {
+		return nil, err
+	}
+	if ok && entry.service != nil {
+		entry.service.close()
+		slog.Info("ES client rebuilt after config change", "es_config_id", esConfig.ID)
+	}
+	registry.entries[esConfig.ID] = &registryEntry{updatedAt: esConfig.UpdatedAt, service: service}
+	return service, nil
+}
+
+// InvalidateConfig drops the cached query service of an ES config after it was
+// edited (updatedAt is the config's new UpdatedAt) or deleted, so the next execution
+// builds a new client. Configs older than updatedAt can no longer be cached.
+func InvalidateConfig(id uint, updatedAt time.Time) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if entry, ok := registry.entries[id]; ok && entry.service != nil {
+		entry.service.close()
+	}
+	registry.entries[id] = &registryEntry{updatedAt: updatedAt}
+}
+
+// Close releases the idle connections of a service built with NewServiceFromConfig
+// outside the registry, e.g. for a connection test
+func (s *Service) Close() {
+	s.close()
+}
+
+// close releases the idle connections of the service. Requests still in flight
+// finish normally; their connections expire with the transport's idle timeout.
+func (s *Service) close() {
+	if s.transport != nil {
+		s.transport.CloseIdleConnections()
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/kk/elk-helper/backend/internal/models"
 	"github.com/kk/elk-helper/backend/internal/repository/database"
 	"github.com/kk/elk-helper/backend/internal/security"
+	channel_config "github.com/kk/elk-helper/backend/internal/service/channelconfig"
+	lark_config "github.com/kk/elk-helper/backend/internal/service/larkconfig"
 	"gorm.io/gorm"
 )
 
@@ -31,7 +33,7 @@ func (s *Service) GetAll() ([]models.Rule, error) {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if err := db.Preload("LarkConfig").Preload("ESConfig").Find(&rules).Error; err != nil {
+	if err := db.Preload("ESConfig").Preload("Channels").Preload("Channels.Template").Preload("Template").Preload("Schedules").Find(&rules).Error; err != nil {
 		return nil, fmt.Errorf("failed to get rules: %w", err)
 	}
 
@@ -65,7 +67,7 @@ func (s *Service) GetAllPaged(page, pageSize int) ([]models.Rule, int64, error)
 		return nil, 0, fmt.Errorf("failed to count rules: %w", err)
 	}
 
-	if err := db.Preload("LarkConfig").Preload("ESConfig").
+	if err := db.Preload("ESConfig").Preload("Channels").Preload("Channels.Template").Preload("Template").Preload("Schedules").
 		Order("id DESC").
 		Offset(offset).
 		Limit(pageSize).
@@ -86,7 +88,7 @@ func (s *Service) GetByID(id uint) (*models.Rule, error) {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if err := db.Preload("LarkConfig").Preload("ESConfig").First(&rule, id).Error; err != nil {
+	if err := db.Preload("ESConfig").Preload("Channels").Preload("Channels.Template").Preload("Template").Preload("Schedules").First(&rule, id).Error; err != nil {
 		return nil, fmt.Errorf("rule not found: %w", err)
 	}
 	if err := decryptRuleSecretInPlace(&rule); err != nil {
@@ -101,7 +103,7 @@ func (s *Service) GetByName(name string) (*models.Rule, error) {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if err := db.Preload("LarkConfig").Preload("ESConfig").Where("name = ?", name).First(&rule).Error; err != nil {
+	if err := db.Preload("ESConfig").Preload("Channels").Preload("Channels.Template").Preload("Template").Preload("Schedules").Where("name = ?", name).First(&rule).Error; err != nil {
 		return nil, fmt.Errorf("rule not found: %w", err)
 	}
 	if err := decryptRuleSecretInPlace(&rule); err != nil {
@@ -115,25 +117,28 @@ func (s *Service) Create(rule *models.Rule) error {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if rule.LarkWebhook != "" {
-		enc, err := security.MaybeEncrypt(rule.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt rule webhook: %w", err)
-		}
-		rule.LarkWebhook = enc
+	// Rules written with the flat queries list only keep their semantics as a condition tree
+	if rule.Conditions == nil && rule.Queries != nil {
+		rule.Conditions = models.ConditionsFromQueries(rule.Queries)
 	}
 
-	if err := db.Create(rule).Error; err != nil {
-		return fmt.Errorf("failed to create rule: %w", err)
+	larkChannelID, err := larkChannelOf(rule)
+	if err != nil {
+		return err
 	}
 
-	if rule.LarkWebhook != "" {
-		plain, err := security.MaybeDecrypt(rule.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
-		if err == nil {
-			rule.LarkWebhook = plain
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Channels", "Template", "Schedules").Create(rule).Error; err != nil {
+			return fmt.Errorf("failed to create rule: %w", err)
 		}
-	}
-	return nil
+		if err := linkRuleChannels(tx, rule.ID, rule.ChannelIDs, larkChannelID); err != nil {
+			return err
+		}
+		if rule.ScheduleIDs != nil {
+			return replaceRuleSchedules(tx, rule.ID, rule.ScheduleIDs)
+		}
+		return nil
+	})
 }
 
 // Update updates an existing rule
@@ -141,16 +146,100 @@ func (s *Service) Update(id uint, rule *models.Rule) error {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if rule.LarkWebhook != "" {
-		enc, err := security.MaybeEncrypt(rule.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt rule webhook: %w", err)
+	// Rules written with the flat queries list only keep their semantics as a condition tree
+	if rule.Conditions == nil && rule.Queries != nil {
+		rule.Conditions = models.ConditionsFromQueries(rule.Queries)
+	}
+
+	larkChannelID, err := larkChannelOf(rule)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Rule{}).Omit("Channels", "Template", "Schedules").Where("id = ?", id).Updates(rule).Error; err != nil {
+			return fmt.Errorf("failed to update rule: %w", err)
 		}
-		rule.LarkWebhook = enc
+		// nil keeps the current channels and schedules, an empty list clears them
+		if err := linkRuleChannels(tx, id, rule.ChannelIDs, larkChannelID); err != nil {
+			return err
+		}
+		if rule.ScheduleIDs != nil {
+			return replaceRuleSchedules(tx, id, rule.ScheduleIDs)
+		}
+		return nil
+	})
+}
+
+// larkChannelOf returns the lark channel standing in for the Lark config or direct
+// webhook a client still sends, or 0 when the rule sets neither. The legacy fields are
+// cleared so they are never stored: alerts are only delivered through rule channels.
+func larkChannelOf(rule *models.Rule) (uint, error) {
+	configID, webhookURL := rule.LarkConfigID, rule.LarkWebhook
+	rule.LarkConfigID, rule.LarkConfig, rule.LarkWebhook = nil, nil, ""
+
+	larkService := lark_config.NewService()
+	switch {
+	case configID != nil:
+		return larkService.ChannelForConfig(*configID)
+	case webhookURL != "":
+		return larkService.ChannelForWebhook(rule.Name, webhookURL)
+	}
+	return 0, nil
+}
+
+// linkRuleChannels applies the channel list of a create or update request (nil keeps
+// the current channels) and adds the lark channel converted by larkChannelOf, if any
+func linkRuleChannels(tx *gorm.DB, ruleID uint, channelIDs []uint, larkChannelID uint) error {
+	if channelIDs != nil {
+		if larkChannelID != 0 {
+			channelIDs = append(channelIDs, larkChannelID)
+		}
+		return replaceRuleChannels(tx, ruleID, channelIDs)
+	}
+	if larkChannelID == 0 {
+		return nil
+	}
+	if err := tx.Exec("INSERT INTO rule_channels (rule_id, channel_config_id) VALUES (?, ?) ON CONFLICT DO NOTHING", ruleID, larkChannelID).Error; err != nil {
+		return fmt.Errorf("failed to link channel %d: %w", larkChannelID, err)
+	}
+	return nil
+}
+
+// replaceRuleChannels replaces the notification channels linked to a rule
+func replaceRuleChannels(tx *gorm.DB, ruleID uint, channelIDs []uint) error {
+	if err := tx.Exec("DELETE FROM rule_channels WHERE rule_id = ?", ruleID).Error; err != nil {
+		return fmt.Errorf("failed to clear rule channels: %w", err)
+	}
+
+	seen := make(map[uint]bool, len(channelIDs))
+	for _, channelID := range channelIDs {
+		if channelID == 0 || seen[channelID] {
+			continue
+		}
+		seen[channelID] = true
+		if err := tx.Exec("INSERT INTO rule_channels (rule_id, channel_config_id) VALUES (?, ?)", ruleID, channelID).Error; err != nil {
+			return fmt.Errorf("failed to link channel %d: %w", channelID, err)
+		}
+	}
+	return nil
+}
+
+// replaceRuleSchedules replaces the schedules linked to a rule
+func replaceRuleSchedules(tx *gorm.DB, ruleID uint, scheduleIDs []uint) error {
+	if err := tx.Exec("DELETE FROM rule_schedules WHERE rule_id = ?", ruleID).Error; err != nil {
+		return fmt.Errorf("failed to clear rule schedules: %w", err)
 	}
 
-	if err := db.Model(&models.Rule{}).Where("id = ?", id).Updates(rule).Error; err != nil {
-		return fmt.Errorf("failed to update rule: %w", err)
+	seen := make(map[uint]bool, len(scheduleIDs))
+	for _, scheduleID := range scheduleIDs {
+		if scheduleID == 0 || seen[scheduleID] {
+			continue
+		}
+		seen[scheduleID] = true
+		if err := tx.Exec("INSERT INTO rule_schedules (rule_id, schedule_id) VALUES (?, ?)", ruleID, scheduleID).Error; err != nil {
+			return fmt.Errorf("failed to link schedule %d: %w", scheduleID, err)
+		}
 	}
 	return nil
 }
@@ -193,7 +282,7 @@ func (s *Service) GetEnabled() ([]models.Rule, error) {
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if err := db.Preload("LarkConfig").Preload("ESConfig").Where("enabled = ?", true).Find(&rules).Error; err != nil {
+	if err := db.Preload("ESConfig").Preload("Channels").Preload("Channels.Template").Preload("Template").Preload("Schedules").Where("enabled = ?", true).Find(&rules).Error; err != nil {
 		return nil, fmt.Errorf("failed to get enabled rules: %w", err)
 	}
 	if err := decryptRuleSecrets(rules); err != nil {
@@ -213,6 +302,17 @@ func (s *Service) UpdateLastRunTime(id uint, lastRunTime *time.Time) error {
 	return nil
 }
 
+// UpdateLastWindowEnd stores the end of the query window a rule last evaluated
+func (s *Service) UpdateLastWindowEnd(id uint, windowEnd *time.Time) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Model(&models.Rule{}).Where("id = ?", id).Update("last_window_end", windowEnd).Error; err != nil {
+		return fmt.Errorf("failed to update last window end: %w", err)
+	}
+	return nil
+}
+
 // EnableRule enables a rule
 func (s *Service) EnableRule(id uint) error {
 	db, cancel := database.WithTimeout(context.Background())
@@ -286,38 +386,84 @@ func (s *Service) Clone(id uint, newName string) (*models.Rule, error) {
 		Name:         newName,
 		IndexPattern: original.IndexPattern,
 		Queries:      original.Queries,
+		Conditions:   original.Conditions,
 		Enabled:      original.Enabled, // Inherit enabled status from original rule
 		Interval:     original.Interval,
+		ResolveAfter: original.ResolveAfter,
 		ESConfigID:   original.ESConfigID,
-		LarkWebhook:  original.LarkWebhook,
-		LarkConfigID: original.LarkConfigID,
+		ChannelIDs:   channelIDsOf(original),
+		ScheduleIDs:  scheduleIDsOf(original),
 		Description:  original.Description,
+		Labels:       original.Labels,
+
+		QueryMode:     original.QueryMode,
+		QueryDSL:      original.QueryDSL,
+		QueryString:   original.QueryString,
+		QueryLanguage: original.QueryLanguage,
+
+		DisplayPreset: original.DisplayPreset,
+		DisplayFields: original.DisplayFields,
+		TemplateID:    original.TemplateID,
+
+		GroupBy:          original.GroupBy,
+		RenotifyInterval: original.RenotifyInterval,
+
+		TimestampField:  original.TimestampField,
+		WindowTime:      original.WindowTime,
+		EvaluationDelay: original.EvaluationDelay,
+
+		Type:        original.Type,
+		Threshold:   original.Threshold,
+		Aggregation: original.Aggregation,
+		Ratio:       original.Ratio,
+		Absence:     original.Absence,
+
 		// Statistics fields are not copied - they start fresh
-		LastRunTime: nil,
-		RunCount:    0,
-		AlertCount:  0,
+		LastRunTime:   nil,
+		LastWindowEnd: nil,
+		RunCount:      0,
+		AlertCount:    0,
 	}
 
 	// Create the cloned rule
 	db, cancel := database.WithTimeout(context.Background())
 	defer cancel()
 
-	if clonedRule.LarkWebhook != "" {
-		enc, err := security.MaybeEncrypt(clonedRule.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt rule webhook: %w", err)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Channels", "Schedules").Create(&clonedRule).Error; err != nil {
+			return fmt.Errorf("failed to create cloned rule: %w", err)
 		}
-		clonedRule.LarkWebhook = enc
-	}
-
-	if err := db.Create(&clonedRule).Error; err != nil {
-		return nil, fmt.Errorf("failed to create cloned rule: %w", err)
+		if err := replaceRuleChannels(tx, clonedRule.ID, clonedRule.ChannelIDs); err != nil {
+			return err
+		}
+		return replaceRuleSchedules(tx, clonedRule.ID, clonedRule.ScheduleIDs)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload with associations
 	return s.GetByID(clonedRule.ID)
 }
 
+// channelIDsOf returns the IDs of the channels linked to a rule
+func channelIDsOf(rule *models.Rule) []uint {
+	ids := make([]uint, 0, len(rule.Channels))
+	for _, ch := range rule.Channels {
+		ids = append(ids, ch.ID)
+	}
+	return ids
+}
+
+// scheduleIDsOf returns the IDs of the schedules linked to a rule
+func scheduleIDsOf(rule *models.Rule) []uint {
+	ids := make([]uint, 0, len(rule.Schedules))
+	for _, s := range rule.Schedules {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
 func decryptRuleWebhooks(rules []models.Rule) error {
 	return decryptRuleSecrets(rules)
 }
@@ -332,22 +478,11 @@ func decryptRuleSecrets(rules []models.Rule) error {
 }
 
 func decryptRuleSecretInPlace(rule *models.Rule) error {
-	// Rule direct webhook
-	if rule.LarkWebhook != "" {
-		plain, err := security.MaybeDecrypt(rule.LarkWebhook, appconfig.AppConfig.Security.EncryptionKey)
-		if err != nil {
-			return fmt.Errorf("failed to decrypt rule webhook: %w", err)
-		}
-		rule.LarkWebhook = plain
-	}
-
-	// LarkConfig webhook (when rules use config rather than direct webhook)
-	if rule.LarkConfig != nil && rule.LarkConfig.WebhookURL != "" {
-		plain, err := security.MaybeDecrypt(rule.LarkConfig.WebhookURL, appconfig.AppConfig.Security.EncryptionKey)
-		if err != nil {
-			return fmt.Errorf("failed to decrypt lark config webhook: %w", err)
+	// Notification channel settings
+	for i := range rule.Channels {
+		if err := channel_config.DecryptSettings(&rule.Channels[i]); err != nil {
+			return err
 		}
-		rule.LarkConfig.WebhookURL = plain
 	}
 
 	// ESConfig password (used by executor/query service)
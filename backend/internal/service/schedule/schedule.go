@@ -0,0 +1,108 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/repository/database"
+)
+
+// Service provides schedule management operations
+type Service struct{}
+
+// NewService creates a new schedule service
+func NewService() *Service {
+	return &Service{}
+}
+
+// GetAll returns all schedules
+func (s *Service) GetAll() ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Order("id ASC").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// GetByID returns a schedule by ID
+func (s *Service) GetByID(id uint) (*models.Schedule, error) {
+	var schedule models.Schedule
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.First(&schedule, id).Error; err != nil {
+		return nil, fmt.Errorf("schedule not found: %w", err)
+	}
+	return &schedule, nil
+}
+
+// GetByName returns a schedule by name
+func (s *Service) GetByName(name string) (*models.Schedule, error) {
+	var schedule models.Schedule
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Where("name = ?", name).First(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("schedule not found: %w", err)
+	}
+	return &schedule, nil
+}
+
+// Create creates a new schedule
+func (s *Service) Create(schedule *models.Schedule) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Create(schedule).Error; err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+	return nil
+}
+
+// Update updates an existing schedule
+func (s *Service) Update(id uint, schedule *models.Schedule) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	updateData := map[string]interface{}{
+		"name":        schedule.Name,
+		"kind":        schedule.Kind,
+		"timezone":    schedule.Timezone,
+		"windows":     schedule.Windows,
+		"description": schedule.Description,
+	}
+
+	if err := db.Model(&models.Schedule{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a schedule (hard delete)
+func (s *Service) Delete(id uint) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	// Check if any rules are using this schedule
+	var count int64
+	if err := db.Table("rule_schedules").Where("schedule_id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check rule usage: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot delete: %d rules are using this schedule", count)
+	}
+
+	if err := db.Unscoped().Delete(&models.Schedule{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package silence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/repository/database"
+	"gorm.io/gorm"
+)
+
+// Service provides silence management operations
+type Service struct{}
+
+// NewService creates a new silence service
+func NewService() *Service {
+	return &Service{}
+}
+
+// GetAll returns silences, newest first. state filters by pending, active or
+// expired; silences expire automatically once ends_at has passed.
+func (s *Service) GetAll(state string) ([]models.Silence, error) {
+	var silences []models.Silence
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	filterState := func(tx *gorm.DB) *gorm.DB {
+		switch models.SilenceState(state) {
+		case models.SilenceStatePending:
+			return tx.Where("starts_at > ?", now)
+		case models.SilenceStateActive:
+			return tx.Where("starts_at <= ? AND ends_at > ?", now, now)
+		case models.SilenceStateExpired:
+			return tx.Where("ends_at <= ?", now)
+		default:
+			return tx
+		}
+	}
+
+	if err := db.Scopes(filterState).Order("created_at DESC").Find(&silences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get silences: %w", err)
+	}
+	for i := range silences {
+		silences[i].State = silences[i].StateAt(now)
+	}
+	return silences, nil
+}
+
+// GetActive returns the silences in effect at t
+func (s *Service) GetActive(t time.Time) ([]models.Silence, error) {
+	var silences []models.Silence
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Where("starts_at <= ? AND ends_at > ?", t, t).Order("id ASC").Find(&silences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active silences: %w", err)
+	}
+	for i := range silences {
+		silences[i].State = models.SilenceStateActive
+	}
+	return silences, nil
+}
+
+// GetByID returns a silence by ID
+func (s *Service) GetByID(id uint) (*models.Silence, error) {
+	var silence models.Silence
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.First(&silence, id).Error; err != nil {
+		return nil, fmt.Errorf("silence not found: %w", err)
+	}
+	silence.State = silence.StateAt(time.Now())
+	return &silence, nil
+}
+
+// Create creates a new silence
+func (s *Service) Create(silence *models.Silence) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Create(silence).Error; err != nil {
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+	silence.State = silence.StateAt(time.Now())
+	return nil
+}
+
+// Update updates the time range, comment and matchers of a silence
+func (s *Service) Update(id uint, silence *models.Silence) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	updateData := map[string]interface{}{
+		"starts_at": silence.StartsAt,
+		"ends_at":   silence.EndsAt,
+		"comment":   silence.Comment,
+		"matchers":  silence.Matchers,
+	}
+
+	if err := db.Model(&models.Silence{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+		return fmt.Errorf("failed to update silence: %w", err)
+	}
+	return nil
+}
+
+// Expire ends a pending or active silence now. It returns false if the silence has already expired.
+func (s *Service) Expire(id uint) (bool, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	// A pending silence is expired by moving its start to now as well
+	result := db.Model(&models.Silence{}).
+		Where("id = ? AND ends_at > ?", id, now).
+		Updates(map[string]interface{}{
+			"starts_at": gorm.Expr("LEAST(starts_at, ?)", now),
+			"ends_at":   now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to expire silence: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete deletes a silence (hard delete)
+func (s *Service) Delete(id uint) error {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
+	if err := db.Unscoped().Delete(&models.Silence{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete silence: %w", err)
+	}
+	return nil
+}
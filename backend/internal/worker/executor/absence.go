@@ -0,0 +1,64 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/query"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// executeAbsence counts the matching logs in the rule's lookback window and alerts
+// when fewer than expected arrived; the alert resolves once logs reappear
+func (e *Executor) executeAbsence(ctx context.Context, ruleModel *models.Rule, queryService *query.Service, targets []notifier.Target, currentTime time.Time) error {
+	absence := ruleModel.Absence
+	if absence == nil {
+		absence = &models.AbsenceConfig{}
+	}
+
+	window := ruleModel.LookbackWindow(absence.Window)
+	toTime := ruleModel.WindowEnd(currentTime)
+	fromTime := toTime.Add(-window)
+
+	slog.Info("Checking log absence", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+	count, samples, err := queryService.CountLogs(ctx, ruleModel, fromTime, toTime, thresholdSampleSize)
+	if err != nil {
+		slog.Error("Count query failed", "rule_id", ruleModel.ID, "error", err)
+		return fmt.Errorf("count query failed: %w", err)
+	}
+
+	e.recordRun(ruleModel, currentTime)
+
+	expected := absence.Expected()
+	if count >= expected {
+		slog.Info("Expected logs arrived, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "count", count, "expected", expected)
+		go e.recordCleanRunAsync(ruleModel, targets, currentTime)
+		return nil
+	}
+
+	condition := fmt.Sprintf("最近 %s 未收到日志（期望至少 %d 条）", window, expected)
+	if count > 0 {
+		condition = fmt.Sprintf("最近 %s 仅收到 %d 条日志（期望至少 %d 条）", window, count, expected)
+	}
+
+	timeRange := fmt.Sprintf("%s ~ %s", fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"))
+	slog.Info("Logs missing, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "condition", condition, "time_range", timeRange)
+
+	go e.sendAlertAsync(ruleModel, targets, &evaluation{
+		logs:      samples,
+		logCount:  int(count),
+		condition: condition,
+		fromTime:  fromTime,
+		toTime:    toTime,
+		timeRange: timeRange,
+	})
+	return nil
+}
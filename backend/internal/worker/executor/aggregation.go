@@ -0,0 +1,84 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/query"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// executeAggregation evaluates the rule's aggregation over its lookback window and
+// alerts on the buckets whose metric satisfies the condition
+func (e *Executor) executeAggregation(ctx context.Context, ruleModel *models.Rule, queryService *query.Service, targets []notifier.Target, currentTime time.Time) error {
+	agg := ruleModel.Aggregation
+	if agg == nil {
+		return fmt.Errorf("aggregation rule has no aggregation configured")
+	}
+
+	window := ruleModel.LookbackWindow(agg.Window)
+	toTime := ruleModel.WindowEnd(currentTime)
+	fromTime := toTime.Add(-window)
+
+	slog.Info("Aggregating logs", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "metric", agg.MetricLabel(), "group_by", agg.GroupBy, "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+	buckets, err := queryService.AggregateLogs(ctx, ruleModel, fromTime, toTime)
+	if err != nil {
+		slog.Error("Aggregation query failed", "rule_id", ruleModel.ID, "error", err)
+		return fmt.Errorf("aggregation query failed: %w", err)
+	}
+
+	e.recordRun(ruleModel, currentTime)
+
+	offending := make([]models.AggregationBucket, 0)
+	logCount := 0
+	for _, b := range buckets {
+		if agg.Holds(b.Value) {
+			offending = append(offending, b)
+			logCount += int(b.DocCount)
+		}
+	}
+	if len(offending) == 0 {
+		slog.Info("No bucket meets the condition, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "buckets", len(buckets))
+		go e.recordCleanRunAsync(ruleModel, targets, currentTime)
+		return nil
+	}
+
+	condition := fmt.Sprintf("最近 %s %s %s %s", window, agg.MetricLabel(), agg.Operator, models.FormatMetricValue(agg.Threshold))
+	if agg.GroupBy != "" {
+		condition = fmt.Sprintf("最近 %s 按 %s 分桶，%s %s %s（%d 个桶满足）", window, agg.GroupBy, agg.MetricLabel(), agg.Operator, models.FormatMetricValue(agg.Threshold), len(offending))
+	}
+
+	timeRange := fmt.Sprintf("%s ~ %s", fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"))
+	slog.Info("Aggregation condition met, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "condition", condition, "time_range", timeRange)
+
+	go e.sendAlertAsync(ruleModel, targets, &evaluation{
+		logCount:  logCount,
+		condition: condition,
+		buckets:   offending,
+		fromTime:  fromTime,
+		toTime:    toTime,
+		timeRange: timeRange,
+	})
+	return nil
+}
+
+// bucketRecords converts aggregation buckets into alert log records for storage
+func bucketRecords(buckets []models.AggregationBucket) []map[string]interface{} {
+	records := make([]map[string]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		records = append(records, map[string]interface{}{
+			"key":       b.Key,
+			"doc_count": b.DocCount,
+			"value":     b.Value,
+		})
+	}
+	return records
+}
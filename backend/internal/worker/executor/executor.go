@@ -7,8 +7,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kk/elk-helper/backend/internal/config"
@@ -17,6 +20,7 @@ import (
 	es_config "github.com/kk/elk-helper/backend/internal/service/esconfig"
 	"github.com/kk/elk-helper/backend/internal/service/query"
 	"github.com/kk/elk-helper/backend/internal/service/rule"
+	"github.com/kk/elk-helper/backend/internal/service/silence"
 	"github.com/kk/elk-helper/backend/internal/worker/notifier"
 )
 
@@ -26,7 +30,7 @@ type Executor struct {
 	esConfigService     *es_config.Service
 	ruleService         *rule.Service
 	alertService        *alert.Service
-	notifier            *notifier.LarkClient
+	silenceService      *silence.Service
 	batchSize           int
 	retryTimes          int
 }
@@ -38,6 +42,7 @@ func NewExecutor(defaultQueryService *query.Service, esConfigService *es_config.
 		esConfigService:     esConfigService,
 		ruleService:         ruleService,
 		alertService:        alertService,
+		silenceService:      silence.NewService(),
 		batchSize:           batchSize,
 		retryTimes:          retryTimes,
 	}
@@ -88,27 +93,13 @@ func (e *Executor) ExecuteRuleWithOptions(ctx context.Context, ruleModel *models
 		slog.Info("Proceeding with execution", "rule_id", ruleModel.ID, "time_since_last_run", timeSinceLastRun, "required_interval", requiredInterval)
 	}
 
-	// Get webhook URL from config or fallback to direct URL
-	webhookURL := ruleModel.LarkWebhook
-	if ruleModel.LarkConfigID != nil && ruleModel.LarkConfig != nil && ruleModel.LarkConfig.Enabled {
-		webhookURL = ruleModel.LarkConfig.WebhookURL
-		slog.Info("Using Lark config webhook", "rule_id", ruleModel.ID, "lark_config_id", *ruleModel.LarkConfigID, "webhook_url", webhookURL)
-	} else if ruleModel.LarkWebhook != "" {
-		slog.Info("Using direct webhook", "rule_id", ruleModel.ID, "webhook_url", ruleModel.LarkWebhook)
-	}
-
-	if webhookURL == "" {
-		errMsg := fmt.Sprintf("no webhook URL configured for rule: lark_webhook=%s, lark_config_id=%v, lark_config_loaded=%v, lark_config_enabled=%v",
-			ruleModel.LarkWebhook,
-			ruleModel.LarkConfigID,
-			ruleModel.LarkConfig != nil,
-			ruleModel.LarkConfig != nil && ruleModel.LarkConfig.Enabled)
-		slog.Error("No webhook URL configured", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "details", errMsg)
-		return fmt.Errorf("%s", errMsg)
+	// Resolve notification targets up front so misconfigured rules fail fast
+	targets, err := notifier.ForRule(ruleModel)
+	if err != nil {
+		slog.Error("No notification channel configured", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "details", err)
+		return err
 	}
-
-	// Create notifier for this rule
-	e.notifier = notifier.NewLarkClient(webhookURL)
+	slog.Info("Using notification channels", "rule_id", ruleModel.ID, "channels", targetNames(targets))
 
 	// Get query service based on rule's ES config
 	queryService, err := e.getQueryService(ruleModel)
@@ -116,17 +107,107 @@ func (e *Executor) ExecuteRuleWithOptions(ctx context.Context, ruleModel *models
 		return fmt.Errorf("failed to get query service: %w", err)
 	}
 
-	// Query logs using the adjusted lastRun time (with overlap to prevent data loss)
-	// The overlap ensures we don't miss logs at the boundary
-	slog.Info("Querying logs", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "from_time", lastRun.Format("2006-01-02 15:04:05"), "to_time", currentTime.Format("2006-01-02 15:04:05"))
-	logs, err := queryService.QueryLogs(ctx, ruleModel, lastRun, currentTime, e.batchSize)
+	// Count-based rule types evaluate a condition instead of alerting on every match
+	switch ruleModel.EffectiveType() {
+	case models.RuleTypeThreshold:
+		return e.executeThreshold(ctx, ruleModel, queryService, targets, currentTime)
+	case models.RuleTypeAggregation:
+		return e.executeAggregation(ctx, ruleModel, queryService, targets, currentTime)
+	case models.RuleTypeRatio:
+		return e.executeRatio(ctx, ruleModel, queryService, targets, currentTime)
+	case models.RuleTypeAbsence:
+		return e.executeAbsence(ctx, ruleModel, queryService, targets, currentTime)
+	}
+
+	// Query logs from the end of the previous window (with overlap to prevent data loss)
+	// The overlap ensures we don't miss logs at the boundary. The window end is shifted
+	// back by the evaluation delay so logs that arrive late are still queried.
+	fromTime, toTime := queryWindow(ruleModel, lastRun, currentTime)
+	if !toTime.After(fromTime) {
+		// The evaluation delay grew past the previous window end: wait until the window catches up
+		slog.Info("Query window not reached yet, skipping evaluation", "rule_id", ruleModel.ID, "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+		e.recordRun(ruleModel, currentTime)
+		return nil
+	}
+	slog.Info("Querying logs", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "window_field", ruleModel.WindowField(), "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+	// Only the count and the stored samples are needed unless every log has to be inspected
+	var result *query.QueryResult
+	sampled := !e.needsAllLogs(ruleModel)
+	if sampled {
+		result, err = queryService.SampleLogs(ctx, ruleModel, fromTime, toTime, storedSampleSize, sampleSourceFields(ruleModel, targets))
+	} else {
+		result, err = queryService.QueryLogs(ctx, ruleModel, fromTime, toTime, e.batchSize)
+	}
 	if err != nil {
 		slog.Error("Query failed", "rule_id", ruleModel.ID, "error", err)
 		return fmt.Errorf("query failed: %w", err)
 	}
+	logs, logCount, warning := result.Logs, len(result.Logs), result.Warning()
+	if sampled {
+		logCount = int(result.Total)
+	}
+	if warning != "" {
+		slog.Warn("Query result incomplete", "rule_id", ruleModel.ID, "total_hits", result.Total, "logs_found", len(logs), "warning", warning)
+	}
+	// An incomplete empty result must not count as a clean run: keep lastRun so the window is retried
+	if result.Partial != nil && logCount == 0 {
+		return fmt.Errorf("query returned partial results: %w", result.Partial)
+	}
 
-	slog.Info("Query completed", "rule_id", ruleModel.ID, "logs_found", len(logs))
+	slog.Info("Query completed", "rule_id", ruleModel.ID, "logs_found", logCount, "sampled", sampled, "samples", len(logs))
 
+	e.recordRun(ruleModel, currentTime)
+	if err := e.ruleService.UpdateLastWindowEnd(ruleModel.ID, &toTime); err != nil {
+		slog.Warn("Failed to update last window end", "rule_id", ruleModel.ID, "error", err)
+	}
+
+	if logCount == 0 {
+		slog.Info("No logs matched, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name)
+		go e.recordCleanRunAsync(ruleModel, targets, currentTime)
+		return nil // No logs matched
+	}
+
+	// Send alert and create alert record in a separate goroutine
+	timeRange := fmt.Sprintf("%s ~ %s", fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"))
+	slog.Info("Found logs, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "log_count", logCount, "time_range", timeRange)
+
+	go e.sendAlertAsync(ruleModel, targets, &evaluation{
+		logs:      logs,
+		logCount:  logCount,
+		fromTime:  fromTime,
+		toTime:    toTime,
+		timeRange: timeRange,
+		warning:   warning,
+	})
+
+	return nil
+}
+
+// queryWindow returns the query window of a match rule execution. It starts at the end
+// of the previous window, so changing the evaluation delay neither skips nor re-queries
+// logs; rules without a stored window end start from the (adjusted) last run.
+func queryWindow(ruleModel *models.Rule, lastRun, currentTime time.Time) (time.Time, time.Time) {
+	toTime := ruleModel.WindowEnd(currentTime)
+	if ruleModel.LastWindowEnd != nil {
+		return ruleModel.LastWindowEnd.Add(-2 * time.Second), toTime
+	}
+	return ruleModel.WindowEnd(lastRun), toTime
+}
+
+// evaluation is a rule execution that triggers an alert
+type evaluation struct {
+	logs      []map[string]interface{}   // matched logs, or samples for count-based rule types
+	logCount  int                        // total matched logs
+	condition string                     // condition that held, empty for match rules
+	buckets   []models.AggregationBucket // offending buckets of aggregation rules
+	fromTime  time.Time
+	toTime    time.Time
+	timeRange string
+	warning   string // partial or truncated query result, stored in Alert.ErrorMsg
+}
+
+// recordRun stores the last run time and increments the run count of a rule
+func (e *Executor) recordRun(ruleModel *models.Rule, currentTime time.Time) {
 	// Update last run time immediately after successful query (synchronous to prevent data loss)
 	// This ensures the next query starts from the correct time
 	now := currentTime
@@ -141,49 +222,178 @@ func (e *Executor) ExecuteRuleWithOptions(ctx context.Context, ruleModel *models
 			slog.Warn("Failed to increment run count", "rule_id", ruleModel.ID, "error", err)
 		}
 	}()
+}
 
-	if len(logs) == 0 {
-		slog.Info("No logs matched, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name)
-		return nil // No logs matched
+// sendAlertAsync sends alert asynchronously in a separate goroutine
+func (e *Executor) sendAlertAsync(ruleModel *models.Rule, targets []notifier.Target, ev *evaluation) {
+	logs, fromTime, toTime, timeRange := ev.logs, ev.fromTime, ev.toTime, ev.timeRange
+	slog.Info("sendAlertAsync started", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "log_count", ev.logCount)
+
+	originalLogCount := ev.logCount
+
+	// Only match rules carry every matched log; the others carry samples of a count
+	countBased := ruleModel.EffectiveType() != models.RuleTypeMatch
+
+	// Active silences mute matching logs; muted logs are still recorded on the alert
+	notifyLogs, mutedBy := logs, (*models.Silence)(nil)
+	if silences, err := e.silenceService.GetActive(time.Now()); err != nil {
+		slog.Warn("Failed to get active silences", "rule_id", ruleModel.ID, "error", err)
+	} else if countBased {
+		if mutedBy = silenceAlert(silences, ruleModel); mutedBy != nil {
+			notifyLogs = nil
+		}
+	} else {
+		notifyLogs, mutedBy = silenceLogs(silences, ruleModel, logs)
+	}
+	silenced := mutedBy != nil && len(notifyLogs) == 0
+	if mutedBy != nil {
+		slog.Info("Logs muted by silence", "rule_id", ruleModel.ID, "silence_id", mutedBy.ID, "muted", len(logs)-len(notifyLogs), "log_count", originalLogCount)
 	}
 
-	// Send alert and create alert record in a separate goroutine
-	timeRange := fmt.Sprintf("%s ~ %s", lastRun.Format("2006-01-02 15:04:05"), currentTime.Format("2006-01-02 15:04:05"))
-	slog.Info("Found logs, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "log_count", len(logs), "time_range", timeRange)
+	// Split the matched logs by the fingerprint of the rule's group_by fields;
+	// current covers all logs for the counts, pending only the logs to notify.
+	// Count-based alerts are not grouped: fingerprints of their samples do not
+	// account for the count.
+	grouped := len(ruleModel.GroupBy) > 0 && !countBased && len(logs) > 0
+	var current, pending []*logGroup
+	if grouped {
+		current = groupLogs(logs, ruleModel.GroupBy, toTime)
+		pending = current
+		if len(notifyLogs) != len(logs) {
+			pending = groupLogs(notifyLogs, ruleModel.GroupBy, toTime)
+		}
+	}
+	interval := renotifyInterval(ruleModel)
 
-	go e.sendAlertAsync(ruleModel, logs, lastRun, currentTime, timeRange)
+	// Persist only a capped sample of logs to prevent DB bloat.
+	logsForStorage := logs
+	if ev.buckets != nil {
+		logsForStorage = bucketRecords(ev.buckets)
+	}
+	if len(logsForStorage) > storedSampleSize {
+		logsForStorage = logsForStorage[:storedSampleSize]
+	}
 
-	return nil
-}
+	// Consecutive matching executions fold into the open alert of the rule;
+	// otherwise create the alert record before notifying so channels can reference it.
+	// The delivery status is corrected below if delivery fails.
+	alertRecord, openErr := e.alertService.GetOpenByRule(ruleModel.ID)
+	if openErr != nil {
+		slog.Warn("Failed to get open alert, creating a new one", "rule_id", ruleModel.ID, "error", openErr)
+	}
+	if grouped {
+		// Groups beyond the record's capacity are notified and persisted as one overflow group
+		var stored models.AlertGroups
+		if alertRecord != nil {
+			stored = alertRecord.Groups
+		}
+		admitted := admittedGroups(stored, current)
+		current = foldOverflow(current, admitted, ruleModel.GroupBy, toTime)
+		pending = foldOverflow(pending, admitted, ruleModel.GroupBy, toTime)
+	}
+	if alertRecord != nil {
+		// Keep the start of the original range so the record spans the whole incident
+		foldedRange := timeRange
+		if start, _, ok := strings.Cut(alertRecord.TimeRange, " ~ "); ok {
+			foldedRange = start + " ~ " + toTime.Format("2006-01-02 15:04:05")
+		}
+		previousGroups := alertRecord.Groups
+		var groups models.AlertGroups
+		if grouped {
+			groups = mergeGroups(previousGroups, current, toTime)
+		}
+		if err := e.alertService.RecordFiring(alertRecord, originalLogCount, models.LogData(logsForStorage), groups, foldedRange, toTime, ev.warning); err != nil {
+			slog.Error("Failed to update open alert", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "error", err)
+		}
+		slog.Info("Alert still firing", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "state", alertRecord.State, "fire_count", alertRecord.FireCount)
 
-// sendAlertAsync sends alert asynchronously in a separate goroutine
-func (e *Executor) sendAlertAsync(ruleModel *models.Rule, logs []map[string]interface{}, fromTime, toTime time.Time, timeRange string) {
-	slog.Info("sendAlertAsync started", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "log_count", len(logs))
+		if alertRecord.State == models.AlertStateAcknowledged {
+			slog.Info("Alert acknowledged, skipping notification", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "acknowledged_by", alertRecord.AcknowledgedBy)
+			return
+		}
 
-	originalLogCount := len(logs)
-	logsForNotify := logs
-	if len(logsForNotify) > 10 {
-		// 告警通知只需要少量样本，避免 payload 过大
-		logsForNotify = logsForNotify[:10]
+		if silenced {
+			slog.Info("Alert silenced, skipping notification", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "silence_id", mutedBy.ID)
+			if err := e.alertService.RecordSilenced(alertRecord.ID, mutedBy.ID, ev.warning); err != nil {
+				slog.Error("Failed to record alert silence", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "error", err)
+			}
+			return
+		}
+
+		// Groups already notified within the re-notify interval are not notified again
+		if grouped {
+			var due []*logGroup
+			for _, g := range pending {
+				if dueForNotify(lastNotified(previousGroups, g.Fingerprint), toTime, interval) {
+					due = append(due, g)
+				}
+			}
+			pending = due
+		}
+		if (grouped && len(pending) == 0) || (!grouped && !dueForNotify(alertRecord.LastNotifiedAt, toTime, interval)) {
+			slog.Info("Alert notified within re-notify interval, skipping notification", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "renotify_interval", interval)
+			return
+		}
+	} else {
+		alertRecord = &models.Alert{
+			RuleID:      ruleModel.ID,
+			IndexName:   ruleModel.IndexPattern,
+			LogCount:    originalLogCount,
+			Logs:        models.LogData(logsForStorage),
+			TimeRange:   timeRange,
+			Status:      models.AlertStatusSent,
+			State:       models.AlertStateFiring,
+			FireCount:   1,
+			LastFiredAt: &toTime,
+			ErrorMsg:    ev.warning,
+		}
+		if grouped {
+			alertRecord.Groups = mergeGroups(nil, current, toTime)
+		}
+		if silenced {
+			alertRecord.Status = models.AlertStatusSilenced
+			alertRecord.SilenceID = &mutedBy.ID
+		}
+		if err := e.alertService.Create(alertRecord); err != nil {
+			slog.Error("Failed to create alert record", "rule_id", ruleModel.ID, "error", err)
+			alertRecord = nil
+		}
+		if silenced {
+			slog.Info("Alert silenced, skipping notification", "rule_id", ruleModel.ID, "silence_id", mutedBy.ID)
+			return
+		}
 	}
 
-	// Get webhook URL from config or fallback to direct URL
-	webhookURL := ruleModel.LarkWebhook
-	if ruleModel.LarkConfigID != nil && ruleModel.LarkConfig != nil && ruleModel.LarkConfig.Enabled {
-		webhookURL = ruleModel.LarkConfig.WebhookURL
-		slog.Info("Using Lark config webhook in sendAlertAsync", "rule_id", ruleModel.ID, "lark_config_id", *ruleModel.LarkConfigID, "webhook_url", webhookURL)
-	} else if ruleModel.LarkWebhook != "" {
-		slog.Info("Using direct webhook in sendAlertAsync", "rule_id", ruleModel.ID, "webhook_url", ruleModel.LarkWebhook)
+	// 告警通知只需要少量样本，避免 payload 过大
+	logsForNotify, notifyLogCount := notifyLogs, originalLogCount-(len(logs)-len(notifyLogs))
+	if len(logsForNotify) > notifySampleSize {
+		logsForNotify = logsForNotify[:notifySampleSize]
+	}
+	var notifyGroups []models.AlertGroup
+	if grouped {
+		logsForNotify, notifyLogCount = sampleGroupLogs(pending, notifySampleSize)
+		for _, g := range pending {
+			notifyGroups = append(notifyGroups, g.AlertGroup)
+		}
 	}
 
-	if webhookURL == "" {
-		slog.Error("No webhook URL configured for rule in sendAlertAsync", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name)
-		return
+	msg := &notifier.AlertMessage{
+		Rule:      ruleModel,
+		RuleName:  ruleModel.Name,
+		IndexName: ruleModel.IndexPattern,
+		Logs:      logsForNotify,
+		LogCount:  notifyLogCount,
+		FromTime:  fromTime,
+		ToTime:    toTime,
+		Groups:    notifyGroups,
+		Condition: ev.condition,
+		Buckets:   ev.buckets,
+	}
+	if alertRecord != nil {
+		msg.AlertID = alertRecord.ID
 	}
 
-	// Create notifier for this alert
-	notifier := notifier.NewLarkClient(webhookURL)
-	slog.Info("Sending alert notification", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "webhook_url", webhookURL, "retry_times", e.retryTimes)
+	slog.Info("Sending alert notification", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "channels", targetNames(targets), "groups", len(notifyGroups), "retry_times", e.retryTimes)
 
 	sendTimeout := 20 * time.Second
 	if config.AppConfig != nil && config.AppConfig.Worker.AlertSendTimeoutSeconds > 0 {
@@ -193,58 +403,26 @@ func (e *Executor) sendAlertAsync(ruleModel *models.Rule, logs []map[string]inte
 	sendCtx, cancel := context.WithTimeout(context.Background(), sendTimeout)
 	defer cancel()
 
-	err := func() error {
-		// notifier 内部 http client 有 timeout；这里再用 context 做整体兜底
-		type result struct{ err error }
-		ch := make(chan result, 1)
-		go func() {
-			ch <- result{err: notifier.SendAlert(ruleModel.Name, ruleModel.IndexPattern, logsForNotify, originalLogCount, fromTime, toTime, e.retryTimes)}
-		}()
-
-		select {
-		case r := <-ch:
-			return r.err
-		case <-sendCtx.Done():
-			return fmt.Errorf("alert send timeout after %s", sendTimeout)
-		}
-	}()
-
+	err := e.notifyTargets(sendCtx, targets, msg, sendTimeout)
 	if err != nil {
 		slog.Error("Alert send failed", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "error", err)
 	} else {
 		slog.Info("Alert sent successfully", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name)
 	}
 
-	// Determine alert status
-	alertStatus := models.AlertStatusSent
-	errorMsg := ""
-	if err != nil {
-		alertStatus = models.AlertStatusFailed
-		errorMsg = err.Error()
-	}
-
-	// Persist only a capped sample of logs to prevent DB bloat.
-	logsForStorage := logs
-	if len(logsForStorage) > 50 {
-		logsForStorage = logsForStorage[:50]
-	}
-
-	// Create alert record
-	alertRecord := &models.Alert{
-		RuleID:    ruleModel.ID,
-		IndexName: ruleModel.IndexPattern,
-		LogCount:  originalLogCount,
-		Logs:      models.LogData(logsForStorage),
-		TimeRange: timeRange,
-		Status:    alertStatus,
-		ErrorMsg:  errorMsg,
-	}
-
-	// Create alert record (async)
-	if err := e.alertService.Create(alertRecord); err != nil {
-		slog.Error("Failed to create alert record", "rule_id", ruleModel.ID, "error", err)
-	} else {
-		slog.Info("Alert record created", "rule_id", ruleModel.ID, "alert_status", alertStatus, "log_count", len(logs))
+	if alertRecord != nil {
+		if err != nil {
+			if updateErr := e.alertService.UpdateDeliveryStatus(alertRecord.ID, models.AlertStatusFailed, joinErrorMsg(err.Error(), ev.warning)); updateErr != nil {
+				slog.Error("Failed to update alert record", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "error", updateErr)
+			}
+			alertRecord.Status = models.AlertStatusFailed
+		} else {
+			markNotified(alertRecord.Groups, pending, toTime)
+			if updateErr := e.alertService.RecordNotified(alertRecord.ID, alertRecord.Groups, toTime, ev.warning); updateErr != nil {
+				slog.Error("Failed to record alert notification", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "error", updateErr)
+			}
+		}
+		slog.Info("Alert delivery recorded", "rule_id", ruleModel.ID, "alert_id", alertRecord.ID, "alert_status", alertRecord.Status, "log_count", originalLogCount)
 	}
 
 	// Update alert count if successful (async)
@@ -258,14 +436,108 @@ func (e *Executor) sendAlertAsync(ruleModel *models.Rule, logs []map[string]inte
 	}
 }
 
-// getQueryService returns a query service based on rule's ES config
+// joinErrorMsg combines the delivery error and the query warning of an alert
+func joinErrorMsg(deliveryErr, warning string) string {
+	if warning == "" {
+		return deliveryErr
+	}
+	return deliveryErr + "; " + warning
+}
+
+// recordCleanRunAsync counts executions without matches on the open alert of the rule
+// and resolves it after rule.ResolveAfter consecutive clean runs
+func (e *Executor) recordCleanRunAsync(ruleModel *models.Rule, targets []notifier.Target, currentTime time.Time) {
+	openAlert, err := e.alertService.GetOpenByRule(ruleModel.ID)
+	if err != nil {
+		slog.Warn("Failed to get open alert", "rule_id", ruleModel.ID, "error", err)
+		return
+	}
+	if openAlert == nil {
+		return
+	}
+
+	resolveAfter := ruleModel.ResolveAfter
+	if resolveAfter < 1 {
+		resolveAfter = 1
+	}
+	cleanCount := openAlert.CleanCount + 1
+	if cleanCount < resolveAfter {
+		if err := e.alertService.UpdateCleanCount(openAlert.ID, cleanCount); err != nil {
+			slog.Warn("Failed to update alert clean count", "rule_id", ruleModel.ID, "alert_id", openAlert.ID, "error", err)
+		}
+		slog.Info("Open alert had a clean run", "rule_id", ruleModel.ID, "alert_id", openAlert.ID, "clean_count", cleanCount, "resolve_after", resolveAfter)
+		return
+	}
+
+	resolved, err := e.alertService.Resolve(openAlert.ID)
+	if err != nil {
+		slog.Error("Failed to resolve alert", "rule_id", ruleModel.ID, "alert_id", openAlert.ID, "error", err)
+		return
+	}
+	if !resolved {
+		return
+	}
+
+	// Channels that never heard of the alert (silenced from the start) get no recovery
+	// message; an alert silenced after it was notified still resolves there
+	if openAlert.LastNotifiedAt == nil {
+		slog.Info("Alert was never notified, skipping resolved notification", "rule_id", ruleModel.ID, "alert_id", openAlert.ID, "status", openAlert.Status)
+		return
+	}
+	slog.Info("Rule stopped matching, alert resolved", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "alert_id", openAlert.ID, "channels", targetNames(targets))
+	msg := notifier.NewResolvedMessage(ruleModel, openAlert, currentTime)
+	if err := notifier.SendResolved(targets, msg, e.retryTimes); err != nil {
+		slog.Error("Resolved notification failed", "rule_id", ruleModel.ID, "alert_id", openAlert.ID, "error", err)
+	}
+}
+
+// notifyTargets sends the alert to every target concurrently and joins the failures
+func (e *Executor) notifyTargets(ctx context.Context, targets []notifier.Target, msg *notifier.AlertMessage, sendTimeout time.Duration) error {
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target notifier.Target) {
+			defer wg.Done()
+			// notifier 内部 http client 有 timeout；这里再用 context 做整体兜底
+			ch := make(chan error, 1)
+			// 每个渠道可使用不同的通知模板
+			targetMsg := msg.WithContext(ctx)
+			targetMsg.Template = target.Template
+			go func() {
+				ch <- target.Notifier.SendAlert(targetMsg, e.retryTimes)
+			}()
+
+			select {
+			case err := <-ch:
+				if err != nil {
+					errs[i] = fmt.Errorf("%s(%s): %w", target.Name, target.Notifier.Type(), err)
+				}
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("%s(%s): alert send timeout after %s", target.Name, target.Notifier.Type(), sendTimeout)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func targetNames(targets []notifier.Target) []string {
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.Name+"("+t.Notifier.Type()+")")
+	}
+	return names
+}
+
+// getQueryService returns the shared query service of the rule's ES config
 func (e *Executor) getQueryService(ruleModel *models.Rule) (*query.Service, error) {
 	// If rule has ES config, use it
 	if ruleModel.ESConfigID != nil && ruleModel.ESConfig != nil {
 		if !ruleModel.ESConfig.Enabled {
 			return nil, fmt.Errorf("ES config is disabled")
 		}
-		return query.NewServiceFromConfig(ruleModel.ESConfig)
+		return query.ServiceForConfig(ruleModel.ESConfig)
 	}
 
 	// If rule has ES config ID but ESConfig is not loaded, fetch it
@@ -277,7 +549,7 @@ func (e *Executor) getQueryService(ruleModel *models.Rule) (*query.Service, erro
 		if !esConfig.Enabled {
 			return nil, fmt.Errorf("ES config is disabled")
 		}
-		return query.NewServiceFromConfig(esConfig)
+		return query.ServiceForConfig(esConfig)
 	}
 
 	// Fallback to default query service (using environment variables)
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+const (
+	// maxAlertGroups caps the groups stored on an alert record; groups beyond the cap
+	// are folded into one overflow group so their notification state is persisted too
+	maxAlertGroups = 200
+	// overflowFingerprint identifies the group collecting the groups beyond maxAlertGroups
+	overflowFingerprint = "overflow"
+)
+
+// logGroup is the matched logs of one fingerprint in the current execution
+type logGroup struct {
+	models.AlertGroup
+	logs []map[string]interface{}
+}
+
+// groupLogs splits logs by the fingerprint of their group_by field values,
+// keeping the order in which groups first appear
+func groupLogs(logs []map[string]interface{}, fields []string, seenAt time.Time) []*logGroup {
+	var groups []*logGroup
+	byFingerprint := make(map[string]*logGroup)
+	for _, log := range logs {
+		values := make(map[string]string, len(fields))
+		for _, f := range fields {
+			values[f] = notifier.FieldValue(log, f)
+		}
+		fp := fingerprint(values)
+
+		g, ok := byFingerprint[fp]
+		if !ok {
+			g = &logGroup{AlertGroup: models.AlertGroup{
+				Fingerprint: fp,
+				Values:      values,
+				FirstSeen:   seenAt,
+				LastSeen:    seenAt,
+			}}
+			byFingerprint[fp] = g
+			groups = append(groups, g)
+		}
+		g.Count++
+		g.logs = append(g.logs, log)
+	}
+	return groups
+}
+
+// fingerprint hashes the sorted field=value pairs of a group
+func fingerprint(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(values[k])
+		b.WriteByte('\n')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// admittedGroups returns the fingerprints of the current groups that fit on the alert
+// record: groups already stored always fit, new groups take the free slots in order of
+// appearance. One slot is kept for the overflow group.
+func admittedGroups(existing models.AlertGroups, current []*logGroup) map[string]bool {
+	stored := make(map[string]bool, len(existing))
+	free := maxAlertGroups - 1
+	for _, g := range existing {
+		stored[g.Fingerprint] = true
+		if g.Fingerprint != overflowFingerprint {
+			free--
+		}
+	}
+
+	admitted := make(map[string]bool, len(current))
+	for _, g := range current {
+		switch {
+		case stored[g.Fingerprint]:
+			admitted[g.Fingerprint] = true
+		case free > 0:
+			admitted[g.Fingerprint] = true
+			free--
+		}
+	}
+	return admitted
+}
+
+// foldOverflow folds the groups that are not admitted into a single overflow group
+// whose group_by values read "其余 N 个分组"
+func foldOverflow(groups []*logGroup, admitted map[string]bool, fields []string, seenAt time.Time) []*logGroup {
+	kept := make([]*logGroup, 0, len(groups))
+	var overflow *logGroup
+	folded := 0
+	for _, g := range groups {
+		if admitted[g.Fingerprint] {
+			kept = append(kept, g)
+			continue
+		}
+		if overflow == nil {
+			overflow = &logGroup{AlertGroup: models.AlertGroup{
+				Fingerprint: overflowFingerprint,
+				FirstSeen:   seenAt,
+				LastSeen:    seenAt,
+			}}
+		}
+		overflow.Count += g.Count
+		overflow.logs = append(overflow.logs, g.logs...)
+		folded++
+	}
+	if overflow == nil {
+		return groups
+	}
+
+	overflow.Values = make(map[string]string, len(fields))
+	for _, f := range fields {
+		overflow.Values[f] = fmt.Sprintf("其余 %d 个分组", folded)
+	}
+	return append(kept, overflow)
+}
+
+// mergeGroups adds the counts of the current execution to the groups of the open alert;
+// current must already be capped with foldOverflow
+func mergeGroups(existing models.AlertGroups, current []*logGroup, seenAt time.Time) models.AlertGroups {
+	merged := make(models.AlertGroups, len(existing), len(existing)+len(current))
+	copy(merged, existing)
+
+	index := make(map[string]int, len(merged))
+	for i, g := range merged {
+		index[g.Fingerprint] = i
+	}
+	for _, g := range current {
+		if i, ok := index[g.Fingerprint]; ok {
+			merged[i].Count += g.Count
+			merged[i].LastSeen = seenAt
+			if g.Fingerprint == overflowFingerprint {
+				merged[i].Values = g.Values
+			}
+			continue
+		}
+		index[g.Fingerprint] = len(merged)
+		merged = append(merged, g.AlertGroup)
+	}
+	return merged
+}
+
+// lastNotified returns when a group of the alert was last notified, nil if never
+func lastNotified(groups models.AlertGroups, fp string) *time.Time {
+	for _, g := range groups {
+		if g.Fingerprint == fp {
+			return g.LastNotifiedAt
+		}
+	}
+	return nil
+}
+
+// markNotified records the notification time on the notified groups
+func markNotified(groups models.AlertGroups, notified []*logGroup, at time.Time) {
+	fps := make(map[string]struct{}, len(notified))
+	for _, g := range notified {
+		fps[g.Fingerprint] = struct{}{}
+	}
+	for i := range groups {
+		if _, ok := fps[groups[i].Fingerprint]; ok {
+			groups[i].LastNotifiedAt = &at
+		}
+	}
+}
+
+// dueForNotify reports whether something last notified at last should be notified again
+func dueForNotify(last *time.Time, now time.Time, interval time.Duration) bool {
+	return last == nil || interval <= 0 || now.Sub(*last) >= interval
+}
+
+// renotifyInterval returns the re-notify interval of a rule, 0 to notify on every match
+func renotifyInterval(ruleModel *models.Rule) time.Duration {
+	if ruleModel.RenotifyInterval == nil || *ruleModel.RenotifyInterval <= 0 {
+		return 0
+	}
+	return time.Duration(*ruleModel.RenotifyInterval) * time.Second
+}
+
+// sampleGroupLogs picks up to n logs round-robin across groups so every group is
+// represented, and returns them with the total log count of the groups
+func sampleGroupLogs(groups []*logGroup, n int) ([]map[string]interface{}, int) {
+	var samples []map[string]interface{}
+	total := 0
+	for _, g := range groups {
+		total += g.Count
+	}
+	for i := 0; len(samples) < n && len(samples) < total; i++ {
+		for _, g := range groups {
+			if i < len(g.logs) && len(samples) < n {
+				samples = append(samples, g.logs[i])
+			}
+		}
+	}
+	return samples, total
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/query"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// executeRatio counts the numerator and denominator of a ratio rule over its lookback
+// window and alerts when their ratio satisfies the condition
+func (e *Executor) executeRatio(ctx context.Context, ruleModel *models.Rule, queryService *query.Service, targets []notifier.Target, currentTime time.Time) error {
+	ratioConfig := ruleModel.Ratio
+	if ratioConfig == nil {
+		return fmt.Errorf("ratio rule has no ratio configured")
+	}
+
+	window := ruleModel.LookbackWindow(ratioConfig.Window)
+	toTime := ruleModel.WindowEnd(currentTime)
+	fromTime := toTime.Add(-window)
+
+	slog.Info("Counting ratio", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+	numerator, denominator, samples, err := queryService.CountRatio(ctx, ruleModel, fromTime, toTime, thresholdSampleSize)
+	if err != nil {
+		slog.Error("Ratio query failed", "rule_id", ruleModel.ID, "error", err)
+		return fmt.Errorf("ratio query failed: %w", err)
+	}
+
+	e.recordRun(ruleModel, currentTime)
+
+	if denominator < ratioConfig.MinDenominator {
+		// Too little traffic says nothing about the ratio: the run is recorded but the
+		// open alert neither counts a clean run nor resolves
+		slog.Info("Denominator below min_denominator, skipping evaluation", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "denominator", denominator, "min_denominator", ratioConfig.MinDenominator)
+		return nil
+	}
+
+	ratio, holds := ratioConfig.Evaluate(numerator, denominator)
+	if !holds {
+		slog.Info("Ratio condition not met, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "numerator", numerator, "denominator", denominator, "ratio", ratio)
+		go e.recordCleanRunAsync(ruleModel, targets, currentTime)
+		return nil
+	}
+
+	condition := fmt.Sprintf("最近 %s 比例 %s%%（%d / %d）%s %s%%", window,
+		models.FormatMetricValue(ratio*100), numerator, denominator, ratioConfig.Operator, models.FormatMetricValue(ratioConfig.Threshold*100))
+
+	timeRange := fmt.Sprintf("%s ~ %s", fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"))
+	slog.Info("Ratio condition met, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "condition", condition, "time_range", timeRange)
+
+	go e.sendAlertAsync(ruleModel, targets, &evaluation{
+		logs:      samples,
+		logCount:  int(numerator),
+		condition: condition,
+		fromTime:  fromTime,
+		toTime:    toTime,
+		timeRange: timeRange,
+	})
+	return nil
+}
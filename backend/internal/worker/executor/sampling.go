@@ -0,0 +1,65 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+const (
+	storedSampleSize = 50 // 告警记录保存的日志样本数
+	notifySampleSize = 10 // 通知中展示的日志样本数
+)
+
+// needsAllLogs reports whether a match rule must fetch every matching log instead of
+// the count and a sample: grouping fingerprints each log, and silences with log field
+// matchers mute logs one by one
+func (e *Executor) needsAllLogs(ruleModel *models.Rule) bool {
+	if len(ruleModel.GroupBy) > 0 {
+		return true
+	}
+	silences, err := e.silenceService.GetActive(time.Now())
+	if err != nil {
+		slog.Warn("Failed to get active silences, fetching all logs", "rule_id", ruleModel.ID, "error", err)
+		return true
+	}
+	for i := range silences {
+		if silences[i].MatchesRule(ruleModel) && len(silences[i].FieldMatchers()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// displayFieldChannels are the channel types that render logs only through the rule's
+// display fields; webhooks (and any future type) send whole documents
+var displayFieldChannels = map[string]bool{
+	models.ChannelTypeLark:         true,
+	models.ChannelTypeSlack:        true,
+	models.ChannelTypeDingTalk:     true,
+	models.ChannelTypeWeCom:        true,
+	models.ChannelTypeEmail:        true,
+	models.ChannelTypeAlertmanager: true,
+	models.ChannelTypePagerDuty:    true,
+	models.ChannelTypeOpsgenie:     true,
+}
+
+// sampleSourceFields returns the _source filter of sampled logs, or nil to fetch whole
+// documents. The filter only applies when every target renders the rule's display
+// fields without a template; otherwise a payload may carry any field, and the logs
+// stored on the alert are kept whole as well.
+func sampleSourceFields(ruleModel *models.Rule, targets []notifier.Target) []string {
+	for _, t := range targets {
+		if t.Template != nil || !displayFieldChannels[t.Notifier.Type()] {
+			return nil
+		}
+	}
+	return ruleModel.SourceFields()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// silenceLogs drops the logs muted by active silences. It returns the logs that
+// are still to be notified and the first silence that muted any of them.
+func silenceLogs(silences []models.Silence, ruleModel *models.Rule, logs []map[string]interface{}) ([]map[string]interface{}, *models.Silence) {
+	applicable := ruleSilences(silences, ruleModel)
+	if len(applicable) == 0 {
+		return logs, nil
+	}
+	if len(logs) == 0 {
+		// Alerts without logs (e.g. "count < 1") are muted by rule-level silences only
+		return logs, ruleSilence(applicable)
+	}
+
+	var mutedBy *models.Silence
+	remaining := make([]map[string]interface{}, 0, len(logs))
+	for _, log := range logs {
+		if s := silenceFor(applicable, log); s != nil {
+			if mutedBy == nil {
+				mutedBy = s
+			}
+			continue
+		}
+		remaining = append(remaining, log)
+	}
+	return remaining, mutedBy
+}
+
+// silenceAlert returns the first active silence muting a whole alert of the rule.
+// Count-based rule types carry only samples of the logs they counted, so silences
+// with log field matchers cannot tell whether they mute the count and never apply.
+func silenceAlert(silences []models.Silence, ruleModel *models.Rule) *models.Silence {
+	return ruleSilence(ruleSilences(silences, ruleModel))
+}
+
+// ruleSilences returns the silences matching the rule's name and labels
+func ruleSilences(silences []models.Silence, ruleModel *models.Rule) []*models.Silence {
+	var applicable []*models.Silence
+	for i := range silences {
+		if silences[i].MatchesRule(ruleModel) {
+			applicable = append(applicable, &silences[i])
+		}
+	}
+	return applicable
+}
+
+// ruleSilence returns the first silence without log field matchers
+func ruleSilence(silences []*models.Silence) *models.Silence {
+	for _, s := range silences {
+		if len(s.FieldMatchers()) == 0 {
+			return s
+		}
+	}
+	return nil
+}
+
+// silenceFor returns the first silence whose log field matchers all match the log;
+// silences without field matchers mute every log of the rule
+func silenceFor(silences []*models.Silence, log map[string]interface{}) *models.Silence {
+	for _, s := range silences {
+		matched := true
+		for _, m := range s.FieldMatchers() {
+			if !m.Matches(notifier.FieldValue(log, m.Name)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+	"github.com/kk/elk-helper/backend/internal/service/query"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
+)
+
+// thresholdSampleSize is the number of logs fetched with a count for display
+const thresholdSampleSize = 10
+
+// executeThreshold counts the matching logs in the rule's lookback window with a
+// single track_total_hits search and alerts when the threshold condition holds
+func (e *Executor) executeThreshold(ctx context.Context, ruleModel *models.Rule, queryService *query.Service, targets []notifier.Target, currentTime time.Time) error {
+	threshold := ruleModel.Threshold
+	if threshold == nil {
+		return fmt.Errorf("threshold rule has no threshold configured")
+	}
+
+	window := ruleModel.LookbackWindow(threshold.Window)
+	toTime := ruleModel.WindowEnd(currentTime)
+	fromTime := toTime.Add(-window)
+
+	slog.Info("Counting logs", "rule_id", ruleModel.ID, "index_pattern", ruleModel.IndexPattern, "from_time", fromTime.Format("2006-01-02 15:04:05"), "to_time", toTime.Format("2006-01-02 15:04:05"))
+	count, samples, err := queryService.CountLogs(ctx, ruleModel, fromTime, toTime, thresholdSampleSize)
+	if err != nil {
+		// Partial results (query.ErrPartialResults) end here too: a count missing shards
+		// can neither fire nor resolve the alert, so the run is not recorded
+		slog.Error("Count query failed", "rule_id", ruleModel.ID, "error", err)
+		return fmt.Errorf("count query failed: %w", err)
+	}
+
+	e.recordRun(ruleModel, currentTime)
+
+	condition := fmt.Sprintf("最近 %s 命中 %d 条 %s %d", window, count, threshold.Operator, threshold.Count)
+	if !threshold.Holds(count) {
+		slog.Info("Threshold condition not met, skipping alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "count", count, "operator", threshold.Operator, "threshold", threshold.Count)
+		go e.recordCleanRunAsync(ruleModel, targets, currentTime)
+		return nil
+	}
+
+	timeRange := fmt.Sprintf("%s ~ %s", fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"))
+	slog.Info("Threshold condition met, triggering alert", "rule_id", ruleModel.ID, "rule_name", ruleModel.Name, "condition", condition, "time_range", timeRange)
+
+	go e.sendAlertAsync(ruleModel, targets, &evaluation{
+		logs:      samples,
+		logCount:  int(count),
+		condition: condition,
+		fromTime:  fromTime,
+		toTime:    toTime,
+		timeRange: timeRange,
+	})
+	return nil
+}
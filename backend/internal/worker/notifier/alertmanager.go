@@ -0,0 +1,247 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+const (
+	alertmanagerAlertsPath = "/api/v2/alerts"
+	// minResolveTimeout is the lower bound of endsAt - startsAt when resolve_timeout is not set
+	minResolveTimeout = 5 * time.Minute
+	// maxSampleAnnotation caps the size of the sample_log annotation
+	maxSampleAnnotation = 2048
+)
+
+// AlertmanagerSettings holds the settings of an Alertmanager channel
+type AlertmanagerSettings struct {
+	URL            string            `json:"url"`                       // e.g. http://alertmanager:9093
+	Severity       string            `json:"severity,omitempty"`        // severity 标签，默认 warning
+	Labels         map[string]string `json:"labels,omitempty"`          // 附加标签（如 team、env）
+	ResolveTimeout int               `json:"resolve_timeout,omitempty"` // 秒，endsAt = 检测时间 + resolve_timeout；默认 max(2×规则间隔, 5m)
+	ExternalURL    string            `json:"external_url,omitempty"`    // ELK Helper 访问地址，用于 generatorURL
+	Username       string            `json:"username,omitempty"`        // Basic Auth
+	Password       string            `json:"password,omitempty"`
+	BearerToken    string            `json:"bearer_token,omitempty"`
+}
+
+// amAlert is a postable alert of the Alertmanager v2 API
+type amAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertmanagerClient pushes alerts to Prometheus Alertmanager
+type AlertmanagerClient struct {
+	settings   AlertmanagerSettings
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewAlertmanagerClient creates a new Alertmanager client
+func NewAlertmanagerClient(settings AlertmanagerSettings) *AlertmanagerClient {
+	if settings.Severity == "" {
+		settings.Severity = "warning"
+	}
+	endpoint := strings.TrimRight(settings.URL, "/")
+	if !strings.HasSuffix(endpoint, alertmanagerAlertsPath) {
+		endpoint += alertmanagerAlertsPath
+	}
+	return &AlertmanagerClient{
+		settings: settings,
+		endpoint: endpoint,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (ac *AlertmanagerClient) Type() string {
+	return models.ChannelTypeAlertmanager
+}
+
+// SendAlert pushes the alert to Alertmanager
+func (ac *AlertmanagerClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert to Alertmanager", "rule_name", msg.RuleName, "index_name", msg.IndexName, "log_count", logCount, "retry_times", retryTimes)
+	body, err := json.Marshal([]amAlert{ac.buildAlert(msg, logCount)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+
+	return ac.send(body, msg.RuleName, retryTimes)
+}
+
+// send posts alerts with retry and backoff
+func (ac *AlertmanagerClient) send(body []byte, ruleName string, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		retryable, err := ac.post(body)
+		if err == nil {
+			slog.Info("Alert sent successfully to Alertmanager", "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+		slog.Warn("Alertmanager send failed", "rule_name", ruleName, "attempt", attempt, "error", err)
+
+		if !retryable {
+			return fmt.Errorf("alertmanager API error: %w", err)
+		}
+		if attempt < retryTimes {
+			time.Sleep(backoffWithJitter(attempt))
+		}
+	}
+
+	slog.Error("Failed to send to Alertmanager after all attempts", "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send to Alertmanager after %d attempts: %w", retryTimes, lastErr)
+}
+
+// SendResolve pushes the alert again with endsAt set to the resolve time, which resolves it immediately
+func (ac *AlertmanagerClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved alert to Alertmanager", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+	alert := ac.buildAlert(msg, msg.LogCount)
+	alert.EndsAt = msg.ToTime.UTC().Format(time.RFC3339)
+	body, err := json.Marshal([]amAlert{alert})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+	return ac.send(body, msg.RuleName, retryTimes)
+}
+
+// SendTest pushes a short-lived test alert
+func (ac *AlertmanagerClient) SendTest() error {
+	now := time.Now()
+	labels := ac.baseLabels()
+	labels["alertname"] = "ELKHelperTest"
+	body, err := json.Marshal([]amAlert{{
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary": "测试消息：ELK Helper 连接测试",
+		},
+		StartsAt: now.UTC().Format(time.RFC3339),
+		EndsAt:   now.Add(time.Minute).UTC().Format(time.RFC3339),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+	_, err = ac.post(body)
+	return err
+}
+
+func (ac *AlertmanagerClient) baseLabels() map[string]string {
+	labels := make(map[string]string, len(ac.settings.Labels)+6)
+	for k, v := range ac.settings.Labels {
+		labels[k] = v
+	}
+	labels["source"] = "elk-helper"
+	labels["severity"] = ac.settings.Severity
+	return labels
+}
+
+// buildAlert derives labels from the rule and annotations from the matched logs.
+// Labels must stay stable between runs so Alertmanager groups repeats into one alert;
+// endsAt is pushed forward on every firing and the alert resolves once it passes.
+func (ac *AlertmanagerClient) buildAlert(msg *AlertMessage, logCount int) amAlert {
+	labels := ac.baseLabels()
+	labels["alertname"] = msg.RuleName
+	labels["index_pattern"] = msg.IndexName
+
+	esConfigName := "default"
+	resolveTimeout := time.Duration(ac.settings.ResolveTimeout) * time.Second
+	if resolveTimeout <= 0 {
+		resolveTimeout = minResolveTimeout
+	}
+	if msg.Rule != nil {
+		labels["rule_id"] = strconv.FormatUint(uint64(msg.Rule.ID), 10)
+		if msg.Rule.ESConfig != nil {
+			esConfigName = msg.Rule.ESConfig.Name
+		}
+		if ruleTimeout := 2 * time.Duration(msg.Rule.Interval) * time.Second; ac.settings.ResolveTimeout <= 0 && ruleTimeout > resolveTimeout {
+			resolveTimeout = ruleTimeout
+		}
+	}
+	labels["es_config"] = esConfigName
+
+	annotations := map[string]string{
+		"summary":    fmt.Sprintf("规则「%s」在 %s ~ %s 匹配 %d 条日志", msg.RuleName, formatTime(msg.FromTime), formatTime(msg.ToTime), logCount),
+		"log_count":  strconv.Itoa(logCount),
+		"time_range": fmt.Sprintf("%s ~ %s", formatTime(msg.FromTime), formatTime(msg.ToTime)),
+	}
+	if r := msg.rendered(); r != nil {
+		annotations["summary"] = r.Title
+		annotations["description"] = r.Body
+	}
+	if msg.AlertID > 0 {
+		annotations["alert_id"] = strconv.FormatUint(uint64(msg.AlertID), 10)
+	}
+	if samples := sampleLogs(msg.Logs, 1); len(samples) > 0 {
+		var lines []string
+		for _, f := range msg.logFields(samples[0]) {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.Label, f.Value))
+		}
+		annotations["sample_log"] = truncateUTF8(strings.Join(lines, "\n"), maxSampleAnnotation)
+	}
+
+	alert := amAlert{
+		Labels:      labels,
+		Annotations: annotations,
+		StartsAt:    msg.FromTime.UTC().Format(time.RFC3339),
+		EndsAt:      msg.ToTime.Add(resolveTimeout).UTC().Format(time.RFC3339),
+	}
+	if ac.settings.ExternalURL != "" {
+		alert.GeneratorURL = strings.TrimRight(ac.settings.ExternalURL, "/") + "/alerts"
+	}
+	return alert
+}
+
+// post sends the alerts once and reports whether the failure is worth retrying
+func (ac *AlertmanagerClient) post(body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", ac.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ac.settings.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+ac.settings.BearerToken)
+	} else if ac.settings.Username != "" {
+		req.SetBasicAuth(ac.settings.Username, ac.settings.Password)
+	}
+
+	resp, err := ac.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	default:
+		return false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// DingTalkSettings holds the settings of a DingTalk group robot
+type DingTalkSettings struct {
+	WebhookURL string   `json:"webhook_url"`
+	Secret     string   `json:"secret,omitempty"`     // 加签密钥（SEC 开头），为空则不签名
+	AtAll      bool     `json:"at_all,omitempty"`     // 是否 @所有人
+	AtMobiles  []string `json:"at_mobiles,omitempty"` // 需要 @ 的手机号
+}
+
+// DingTalkClient handles DingTalk group robot notifications
+type DingTalkClient struct {
+	settings   DingTalkSettings
+	httpClient *http.Client
+}
+
+// NewDingTalkClient creates a new DingTalk client
+func NewDingTalkClient(settings DingTalkSettings) *DingTalkClient {
+	return &DingTalkClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (dc *DingTalkClient) Type() string {
+	return models.ChannelTypeDingTalk
+}
+
+// SendAlert sends alert message with logs to DingTalk as markdown
+func (dc *DingTalkClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert to DingTalk", "rule_name", msg.RuleName, "index_name", msg.IndexName, "log_count", logCount, "signed", dc.settings.Secret != "", "retry_times", retryTimes)
+
+	title := fmt.Sprintf("🚨 ELK 告警：%s", msg.RuleName)
+	// DingTalk markdown needs a blank line to break lines
+	var text string
+	if r := msg.rendered(); r != nil {
+		title = r.Title
+		text = "## " + r.Title + "\n\n" + strings.ReplaceAll(r.Body, "\n", "\n\n")
+	} else {
+		text = renderMarkdown(msg, logCount, markdownStyle{
+			LineBreak: "\n\n",
+			Highlight: func(v, color string) string { return fmt.Sprintf("<font color=%s>%s</font>", colorHex(color), v) },
+		})
+	}
+	for _, mobile := range dc.settings.AtMobiles {
+		text += "\n\n@" + mobile
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": title,
+			"text":  text,
+		},
+		"at": map[string]interface{}{
+			"isAtAll":   dc.settings.AtAll,
+			"atMobiles": dc.settings.AtMobiles,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return sendRobotWithRetry(dc.httpClient, "DingTalk", msg.RuleName, dc.signedURL, body, retryTimes)
+}
+
+// SendResolve sends a resolved notification to DingTalk as markdown
+func (dc *DingTalkClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved notification to DingTalk", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": fmt.Sprintf("%s：%s", resolvedTitle, msg.RuleName),
+			"text":  "## " + resolvedTitle + "\n\n" + renderResolvedMarkdown(msg, "\n\n"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return sendRobotWithRetry(dc.httpClient, "DingTalk", msg.RuleName, dc.signedURL, body, retryTimes)
+}
+
+// SendTest sends a plain text test message to DingTalk
+func (dc *DingTalkClient) SendTest() error {
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content": "测试消息：ELK Helper 连接测试",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	url, err := dc.signedURL()
+	if err != nil {
+		return err
+	}
+	return postRobot(dc.httpClient, url, body)
+}
+
+// signedURL appends timestamp and sign query parameters when a secret is configured.
+// sign = urlEncode(base64(HmacSHA256(timestamp + "\n" + secret, secret)))
+func (dc *DingTalkClient) signedURL() (string, error) {
+	if dc.settings.Secret == "" {
+		return dc.settings.WebhookURL, nil
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	sign := dingTalkSign(timestamp, dc.settings.Secret)
+
+	u, err := url.Parse(dc.settings.WebhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook url: %w", err)
+	}
+	q := u.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("sign", sign)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func dingTalkSign(timestamp, secret string) string {
+	stringToSign := timestamp + "\n" + strings.TrimSpace(secret)
+	mac := hmac.New(sha256.New, []byte(strings.TrimSpace(secret)))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// EmailSettings holds the SMTP settings of an email channel
+type EmailSettings struct {
+	Host               string   `json:"host"`
+	Port               int      `json:"port"`                           // 默认 587（tls=true 时默认 465）
+	Username           string   `json:"username,omitempty"`             // 为空则不认证
+	Password           string   `json:"password,omitempty"`             // 随渠道配置加密存储
+	From               string   `json:"from"`                           // 发件人地址
+	To                 []string `json:"to"`                             // 收件人列表
+	Cc                 []string `json:"cc,omitempty"`                   // 抄送列表
+	StartTLS           bool     `json:"starttls,omitempty"`             // 明文连接后升级为 TLS
+	TLS                bool     `json:"tls,omitempty"`                  // 直接使用 TLS 连接（SMTPS）
+	InsecureSkipVerify bool     `json:"insecure_skip_verify,omitempty"` // 跳过证书校验（仅测试环境）
+	SubjectPrefix      string   `json:"subject_prefix,omitempty"`       // 邮件主题前缀，默认 "[ELK 告警]"
+}
+
+// Validate checks the required SMTP settings
+func (s *EmailSettings) Validate() error {
+	if s.Host == "" {
+		return fmt.Errorf("email host is required")
+	}
+	if s.From == "" {
+		return fmt.Errorf("email from is required")
+	}
+	if len(s.To) == 0 {
+		return fmt.Errorf("email to is required")
+	}
+	if s.TLS && s.StartTLS {
+		return fmt.Errorf("email tls and starttls are mutually exclusive")
+	}
+	return nil
+}
+
+// EmailClient handles SMTP email notifications
+type EmailClient struct {
+	settings EmailSettings
+	timeout  time.Duration
+}
+
+// NewEmailClient creates a new email client
+func NewEmailClient(settings EmailSettings) *EmailClient {
+	if settings.Port == 0 {
+		settings.Port = 587
+		if settings.TLS {
+			settings.Port = 465
+		}
+	}
+	if settings.SubjectPrefix == "" {
+		settings.SubjectPrefix = "[ELK 告警]"
+	}
+	return &EmailClient{
+		settings: settings,
+		timeout:  10 * time.Second,
+	}
+}
+
+// Type returns the channel type
+func (ec *EmailClient) Type() string {
+	return models.ChannelTypeEmail
+}
+
+// SendAlert sends alert email with an HTML table of sampled logs and a text fallback
+func (ec *EmailClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert email", "rule_name", msg.RuleName, "index_name", msg.IndexName, "log_count", logCount, "recipients", len(ec.recipients()), "retry_times", retryTimes)
+
+	subject := fmt.Sprintf("%s %s（%d 条）", ec.settings.SubjectPrefix, msg.RuleName, logCount)
+	text, htmlBody := renderEmailText(msg, logCount), renderEmailHTML(msg, logCount)
+	if r := msg.rendered(); r != nil {
+		subject = ec.settings.SubjectPrefix + " " + r.Title
+		text = r.Title + "\n\n" + r.Body + "\n"
+		htmlBody = renderEmailTemplateHTML(r)
+	}
+	body, err := ec.buildMessage(subject, text, htmlBody)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	return ec.sendWithRetry(body, msg.RuleName, retryTimes)
+}
+
+// sendWithRetry delivers an email with retry and backoff
+func (ec *EmailClient) sendWithRetry(body []byte, ruleName string, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		if lastErr = ec.send(body); lastErr == nil {
+			slog.Info("Alert email sent successfully", "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		slog.Warn("Email send failed", "rule_name", ruleName, "attempt", attempt, "error", lastErr)
+
+		if attempt < retryTimes {
+			time.Sleep(backoffWithJitter(attempt))
+		}
+	}
+
+	slog.Error("Failed to send alert email after all attempts", "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send email after %d attempts: %w", retryTimes, lastErr)
+}
+
+// SendResolve sends a resolved notification email
+func (ec *EmailClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved notification email", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+
+	var text, htmlBody strings.Builder
+	text.WriteString("ELK 告警恢复\n\n")
+	htmlBody.WriteString(`<div style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;font-size:14px;color:#333">`)
+	htmlBody.WriteString(`<h2 style="color:#00A854">` + html.EscapeString(resolvedTitle) + `</h2>`)
+	htmlBody.WriteString(`<table cellpadding="4" style="border-collapse:collapse">`)
+	for _, row := range resolvedRows(msg) {
+		fmt.Fprintf(&text, "%s：%s\n", row[0], row[1])
+		fmt.Fprintf(&htmlBody, `<tr><td><b>%s</b></td><td>%s</td></tr>`, html.EscapeString(row[0]), html.EscapeString(row[1]))
+	}
+	htmlBody.WriteString(`</table></div>`)
+
+	subject := fmt.Sprintf("%s 已恢复：%s", ec.settings.SubjectPrefix, msg.RuleName)
+	body, err := ec.buildMessage(subject, text.String(), htmlBody.String())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	return ec.sendWithRetry(body, msg.RuleName, retryTimes)
+}
+
+// SendTest sends a simple test email
+func (ec *EmailClient) SendTest() error {
+	text := "测试消息：ELK Helper 连接测试"
+	body, err := ec.buildMessage(ec.settings.SubjectPrefix+" 测试邮件", text, "<p>"+html.EscapeString(text)+"</p>")
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	return ec.send(body)
+}
+
+func (ec *EmailClient) recipients() []string {
+	rcpts := make([]string, 0, len(ec.settings.To)+len(ec.settings.Cc))
+	rcpts = append(rcpts, ec.settings.To...)
+	rcpts = append(rcpts, ec.settings.Cc...)
+	return rcpts
+}
+
+// send delivers a raw message over a single SMTP session
+func (ec *EmailClient) send(body []byte) error {
+	addr := net.JoinHostPort(ec.settings.Host, strconv.Itoa(ec.settings.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         ec.settings.Host,
+		InsecureSkipVerify: ec.settings.InsecureSkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: ec.timeout}
+	var conn net.Conn
+	var err error
+	if ec.settings.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * ec.timeout))
+
+	client, err := smtp.NewClient(conn, ec.settings.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if ec.settings.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls failed: %w", err)
+		}
+	}
+
+	if ec.settings.Username != "" {
+		auth := smtp.PlainAuth("", ec.settings.Username, ec.settings.Password, ec.settings.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(ec.settings.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, rcpt := range ec.recipients() {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write email body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage builds a multipart/alternative MIME message with text and HTML parts
+func (ec *EmailClient) buildMessage(subject, text, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + ec.settings.From,
+		"To: " + strings.Join(ec.settings.To, ", "),
+	}
+	if len(ec.settings.Cc) > 0 {
+		headers = append(headers, "Cc: "+strings.Join(ec.settings.Cc, ", "))
+	}
+	headers = append(headers,
+		"Subject: "+mime.BEncoding.Encode("utf-8", subject),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", mw.Boundary()),
+	)
+	head := strings.Join(headers, "\r\n") + "\r\n\r\n"
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(head), buf.Bytes()...), nil
+}
+
+// renderEmailText renders the plain-text fallback body
+func renderEmailText(msg *AlertMessage, logCount int) string {
+	var sb strings.Builder
+	sb.WriteString("ELK 告警\n\n")
+	fmt.Fprintf(&sb, "规则名称：%s\n", msg.RuleName)
+	fmt.Fprintf(&sb, "时间范围：%s ~ %s\n", formatTime(msg.FromTime), formatTime(msg.ToTime))
+	fmt.Fprintf(&sb, "告警数量：%d 条\n", logCount)
+	fmt.Fprintf(&sb, "索引名称：%s\n", msg.IndexName)
+
+	if len(msg.Logs) > 0 {
+		fmt.Fprintf(&sb, "\n日志摘要（共 %d 条，展示前 %d 条）\n", logCount, len(msg.Logs))
+		for i, log := range msg.Logs {
+			fmt.Fprintf(&sb, "\n#%d\n", i+1)
+			for _, f := range msg.logFields(log) {
+				fmt.Fprintf(&sb, "  %s: %s\n", f.Label, f.Value)
+			}
+		}
+	}
+
+	sb.WriteString("\n完整日志详情请登录 ELK Helper 系统查看\n")
+	return sb.String()
+}
+
+// renderEmailHTML renders the HTML body with a table of sampled logs
+func renderEmailHTML(msg *AlertMessage, logCount int) string {
+	var sb strings.Builder
+	sb.WriteString(`<div style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;font-size:14px;color:#333">`)
+	sb.WriteString(`<h2 style="color:#d93026">🚨 ELK 告警</h2>`)
+	sb.WriteString(`<table cellpadding="4" style="border-collapse:collapse">`)
+	for _, row := range [][2]string{
+		{"📋 规则名称", msg.RuleName},
+		{"⏰ 时间范围", formatTime(msg.FromTime) + " ~ " + formatTime(msg.ToTime)},
+		{"🔔 告警数量", fmt.Sprintf("%d 条", logCount)},
+		{"📊 索引名称", msg.IndexName},
+	} {
+		fmt.Fprintf(&sb, `<tr><td><b>%s</b></td><td>%s</td></tr>`, html.EscapeString(row[0]), html.EscapeString(row[1]))
+	}
+	sb.WriteString(`</table>`)
+
+	if len(msg.Logs) > 0 {
+		// Columns are the union of field labels across samples, in first-seen order
+		var columns []string
+		seen := make(map[string]bool)
+		rows := make([]map[string]logField, 0, len(msg.Logs))
+		for _, log := range msg.Logs {
+			row := make(map[string]logField)
+			for _, f := range msg.logFields(log) {
+				if !seen[f.Label] {
+					seen[f.Label] = true
+					columns = append(columns, f.Label)
+				}
+				row[f.Label] = f
+			}
+			rows = append(rows, row)
+		}
+
+		fmt.Fprintf(&sb, `<h3>📝 日志摘要（共 %d 条，展示前 %d 条）</h3>`, logCount, len(msg.Logs))
+		sb.WriteString(`<table border="1" cellpadding="6" style="border-collapse:collapse;border-color:#ddd">`)
+		sb.WriteString(`<tr style="background:#f5f5f5"><th>#</th>`)
+		for _, col := range columns {
+			fmt.Fprintf(&sb, `<th>%s</th>`, html.EscapeString(col))
+		}
+		sb.WriteString(`</tr>`)
+		for i, row := range rows {
+			fmt.Fprintf(&sb, `<tr><td>%d</td>`, i+1)
+			for _, col := range columns {
+				sb.WriteString(`<td style="vertical-align:top">`)
+				if f, ok := row[col]; ok {
+					sb.WriteString(emailHTMLValue(f))
+				}
+				sb.WriteString(`</td>`)
+			}
+			sb.WriteString(`</tr>`)
+		}
+		sb.WriteString(`</table>`)
+	}
+
+	sb.WriteString(`<p style="color:#888">💡 完整日志详情请登录 ELK Helper 系统查看</p></div>`)
+	return sb.String()
+}
+
+// renderEmailTemplateHTML renders a notification template; the markdown body is kept as preformatted text
+func renderEmailTemplateHTML(r *RenderedMessage) string {
+	var sb strings.Builder
+	sb.WriteString(`<div style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;font-size:14px;color:#333">`)
+	fmt.Fprintf(&sb, `<h2 style="color:%s">%s</h2>`, html.EscapeString(colorHex(r.Color)), html.EscapeString(r.Title))
+	fmt.Fprintf(&sb, `<div style="white-space:pre-wrap">%s</div></div>`, html.EscapeString(r.Body))
+	return sb.String()
+}
+
+func emailHTMLValue(f logField) string {
+	value := html.EscapeString(f.Value)
+	switch f.Style {
+	case styleHighlight:
+		return `<b style="color:` + html.EscapeString(colorHex(f.Color)) + `">` + value + `</b>`
+	case styleCode:
+		return `<code>` + value + `</code>`
+	case styleBlock:
+		return `<pre style="margin:0;white-space:pre-wrap;word-break:break-all">` + value + `</pre>`
+	default:
+		return value
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what the test SMTP server received during one session
+type smtpSession struct {
+	tlsBeforeAuth bool
+	auth          string // decoded AUTH PLAIN response: identity\x00username\x00password
+	from          string
+	rcpts         []string
+	data          []byte
+}
+
+// startSMTPServer serves a single SMTP session supporting STARTTLS and AUTH PLAIN on
+// a loopback listener, and returns its port and the received session
+func startSMTPServer(t *testing.T) (int, <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}}
+	sessions := make(chan smtpSession, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		var session smtpSession
+		defer func() { sessions <- session }()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP test")
+		secure := false
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250-localhost")
+				if !secure {
+					tp.PrintfLine("250-STARTTLS")
+				}
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "STARTTLS":
+				tp.PrintfLine("220 ready to start TLS")
+				tlsConn := tls.Server(conn, tlsConfig)
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				tp = textproto.NewConn(tlsConn)
+				secure = true
+			case "AUTH":
+				mechanism, response, _ := strings.Cut(arg, " ")
+				decoded, err := base64.StdEncoding.DecodeString(response)
+				if strings.ToUpper(mechanism) != "PLAIN" || err != nil {
+					tp.PrintfLine("504 unsupported authentication")
+					continue
+				}
+				session.tlsBeforeAuth = secure
+				session.auth = string(decoded)
+				tp.PrintfLine("235 authenticated")
+			case "MAIL":
+				session.from = addressOf(arg)
+				tp.PrintfLine("250 ok")
+			case "RCPT":
+				session.rcpts = append(session.rcpts, addressOf(arg))
+				tp.PrintfLine("250 ok")
+			case "DATA":
+				tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+				if session.data, err = tp.ReadDotBytes(); err != nil {
+					return
+				}
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 command not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, sessions
+}
+
+// addressOf extracts the address of a "FROM:<a@b>" or "TO:<a@b>" argument
+func addressOf(arg string) string {
+	start, end := strings.Index(arg, "<"), strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return arg
+	}
+	return arg[start+1 : end]
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestEmailClientSendAlertStartTLS(t *testing.T) {
+	port, sessions := startSMTPServer(t)
+
+	client := NewEmailClient(EmailSettings{
+		Host:               "127.0.0.1",
+		Port:               port,
+		Username:           "alerts",
+		Password:           "s3cret",
+		From:               "elk-helper@example.com",
+		To:                 []string{"oncall@example.com", "dev@example.com"},
+		Cc:                 []string{"lead@example.com"},
+		StartTLS:           true,
+		InsecureSkipVerify: true,
+	})
+	msg := &AlertMessage{
+		RuleName:  "payment api errors",
+		IndexName: "logs-payment-*",
+		LogCount:  3,
+		Logs:      []map[string]interface{}{{"message": "connection refused", "@timestamp": "2025-01-01T00:00:00Z"}},
+		FromTime:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		ToTime:    time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC),
+	}
+	if err := client.SendAlert(msg, 1); err != nil {
+		t.Fatalf("SendAlert: %v", err)
+	}
+
+	var session smtpSession
+	select {
+	case session = <-sessions:
+	case <-time.After(10 * time.Second):
+		t.Fatal("SMTP session did not finish")
+	}
+
+	if !session.tlsBeforeAuth {
+		t.Error("AUTH was sent before STARTTLS")
+	}
+	if want := "\x00alerts\x00s3cret"; session.auth != want {
+		t.Errorf("AUTH PLAIN = %q, want %q", session.auth, want)
+	}
+	if session.from != "elk-helper@example.com" {
+		t.Errorf("MAIL FROM = %q", session.from)
+	}
+	if got, want := strings.Join(session.rcpts, ","), "oncall@example.com,dev@example.com,lead@example.com"; got != want {
+		t.Errorf("RCPT TO = %q, want %q", got, want)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(session.data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if want := "[ELK 告警] payment api errors（3 条）"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+	if got := m.Header.Get("To"); got != "oncall@example.com, dev@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := m.Header.Get("Cc"); got != "lead@example.com" {
+		t.Errorf("Cc = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", m.Header.Get("Content-Type"), err)
+	}
+	parts := make(map[string]string)
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part body: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+
+	text := parts["text/plain"]
+	for _, want := range []string{"规则名称：payment api errors", "告警数量：3 条", "索引名称：logs-payment-*", "connection refused"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body missing %q:\n%s", want, text)
+		}
+	}
+	htmlBody := parts["text/html"]
+	for _, want := range []string{"ELK 告警", "payment api errors", "3 条", "connection refused"} {
+		if !strings.Contains(htmlBody, want) {
+			t.Errorf("html body missing %q:\n%s", want, htmlBody)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// fieldStyle controls how a log field value is rendered by each channel
+type fieldStyle int
+
+const (
+	styleText      fieldStyle = iota // plain value
+	styleCode                        // inline code
+	styleBlock                       // full-width code block
+	styleHighlight                   // emphasized value (e.g. red status code)
+)
+
+// logField is a channel-neutral key field extracted from a log entry
+type logField struct {
+	Label string
+	Value string
+	Style fieldStyle
+	Color string // highlight colour, only used with styleHighlight
+}
+
+// logFields extracts the key fields of a log entry using the rule's display
+// configuration, falling back to the auto-detected built-in layout
+func (m *AlertMessage) logFields(log map[string]interface{}) []logField {
+	if m.Rule != nil {
+		if fields := m.Rule.ResolveDisplayFields(); len(fields) > 0 {
+			return extractDisplayFields(log, fields)
+		}
+	}
+	return extractLogFields(log, m.RuleName)
+}
+
+// extractLogFields extracts key fields from a log entry with an auto-detected preset
+// Uses rule name to determine the log type and shows relevant fields:
+// - Rule name contains "nginx": response_code, @timestamp, request, cf_ray, domain
+// - Rule name contains "java", "go", "c++", "python", "nodejs", etc.: module, node_ip, message, @timestamp
+func extractLogFields(log map[string]interface{}, ruleName string) []logField {
+	return extractDisplayFields(log, models.DisplayPresets[detectDisplayPreset(log, ruleName)])
+}
+
+// detectDisplayPreset picks the nginx or app preset from the rule name and log fields
+func detectDisplayPreset(log map[string]interface{}, ruleName string) string {
+	// Detect log type from rule name (case insensitive)
+	ruleNameLower := strings.ToLower(ruleName)
+
+	// Check if rule name contains "nginx"
+	if strings.Contains(ruleNameLower, "nginx") {
+		return models.DisplayPresetNginx
+	}
+
+	// Check if rule name contains application log types (java, go, c++, python, nodejs, app, etc.)
+	appLogTypes := []string{"java", "go", "c++", "cpp", "python", "nodejs", "node", "app", "application", "service", "api", "web"}
+	for _, appType := range appLogTypes {
+		if strings.Contains(ruleNameLower, appType) {
+			return models.DisplayPresetApp
+		}
+	}
+
+	// Fallback: try to detect from log fields
+	if _, hasResponseCode := log["response_code"]; hasResponseCode {
+		return models.DisplayPresetNginx
+	}
+
+	// Default fallback to app log format (more generic)
+	return models.DisplayPresetApp
+}
+
+// extractDisplayFields extracts the configured display fields from a log entry
+func extractDisplayFields(log map[string]interface{}, fields models.DisplayFields) []logField {
+	result := make([]logField, 0, len(fields))
+	for _, f := range fields {
+		label := f.Label
+		if label == "" {
+			label = f.Field
+		}
+
+		value := "-"
+		if raw, ok := lookupFirstField(log, f.Field); ok {
+			value = formatDisplayValue(raw, f)
+		}
+
+		field := logField{Label: label, Value: value, Style: styleText}
+		switch f.Style {
+		case models.DisplayStyleCode:
+			field.Style = styleCode
+		case models.DisplayStyleBlock:
+			field.Style = styleBlock
+		}
+		if f.Highlight != "" {
+			field.Style = styleHighlight
+			field.Color = f.Highlight
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// lookupFirstField returns the first non-empty value among "|" separated field paths
+func lookupFirstField(log map[string]interface{}, paths string) (interface{}, bool) {
+	for _, path := range strings.Split(paths, "|") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if val, ok := lookupField(log, path); ok && val != nil && val != "" {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// lookupField resolves a field path: the literal key first (e.g. "@timestamp",
+// flattened "http.status"), then nested objects separated by dots
+func lookupField(log map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := log[path]; ok {
+		return val, true
+	}
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	nested, ok := log[parts[0]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupField(nested, parts[1])
+}
+
+// formatDisplayValue applies format and truncation to a raw field value
+func formatDisplayValue(raw interface{}, f models.DisplayField) string {
+	var value string
+	switch v := raw.(type) {
+	case string:
+		value = v
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		value = string(b)
+	default:
+		value = fmt.Sprintf("%v", v)
+	}
+
+	switch f.Format {
+	case models.DisplayFormatTimestamp:
+		value = formatTimestampValue(value)
+	case models.DisplayFormatPath:
+		if idx := strings.Index(value, "?"); idx > 0 {
+			value = value[:idx]
+		}
+	case models.DisplayFormatOneline:
+		// Replace newlines with spaces for compact display
+		value = strings.ReplaceAll(value, "\n", " ")
+		value = strings.ReplaceAll(value, "\r", "")
+	}
+
+	if f.MaxLength > 0 {
+		if runes := []rune(value); len(runes) > f.MaxLength {
+			value = string(runes[:f.MaxLength]) + "..."
+		}
+	}
+	return value
+}
+
+// formatTimestampValue formats an ISO timestamp as "2006-01-02 15:04:05"
+func formatTimestampValue(timestampStr string) string {
+	// If it's ISO format, try to format it nicely
+	if strings.Contains(timestampStr, "T") {
+		timestampStr = strings.Replace(timestampStr, "T", " ", 1)
+		timestampStr = strings.Replace(timestampStr, "Z", "", 1)
+		// Truncate milliseconds if present
+		if idx := strings.Index(timestampStr, "."); idx > 0 {
+			timestampStr = timestampStr[:idx]
+		}
+	}
+	return timestampStr
+}
+
+// highlightColors maps named highlight colours to hex values
+var highlightColors = map[string]string{
+	"red":    "#FF0000",
+	"orange": "#FF8800",
+	"yellow": "#E6B800",
+	"green":  "#00A854",
+	"blue":   "#1677FF",
+	"purple": "#722ED1",
+	"grey":   "#8C8C8C",
+	"gray":   "#8C8C8C",
+}
+
+// colorHex returns the hex value of a highlight colour, defaulting to red
+func colorHex(color string) string {
+	if strings.HasPrefix(color, "#") {
+		return color
+	}
+	if hex, ok := highlightColors[strings.ToLower(color)]; ok {
+		return hex
+	}
+	return highlightColors["red"]
+}
+
+// colorName returns the named highlight colour, defaulting to red
+func colorName(color string) string {
+	if _, ok := highlightColors[strings.ToLower(color)]; ok {
+		return strings.ToLower(color)
+	}
+	return "red"
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// sampleLogs returns at most n logs for display
+func sampleLogs(logs []map[string]interface{}, n int) []map[string]interface{} {
+	if len(logs) > n {
+		return logs[:n]
+	}
+	return logs
+}
+
+// FieldValue returns the formatted value of a log field path, "" if it is missing
+func FieldValue(log map[string]interface{}, path string) string {
+	raw, ok := lookupField(log, path)
+	if !ok || raw == nil {
+		return ""
+	}
+	return formatDisplayValue(raw, models.DisplayField{})
+}
+
+// groupLines renders at most n alert groups as "`field=value, ...` × count"
+func groupLines(groups []models.AlertGroup, fields []string, n int) []string {
+	lines := make([]string, 0, n+1)
+	for i, g := range groups {
+		if i == n {
+			lines = append(lines, fmt.Sprintf("… 另有 %d 个分组", len(groups)-n))
+			break
+		}
+		pairs := make([]string, 0, len(fields))
+		for _, f := range fields {
+			pairs = append(pairs, f+"="+g.Values[f])
+		}
+		lines = append(lines, fmt.Sprintf("`%s` × %d", strings.Join(pairs, ", "), g.Count))
+	}
+	return lines
+}
+
+// groupFields returns the group_by fields of the alert rule
+func (m *AlertMessage) groupFields() []string {
+	if m.Rule == nil {
+		return nil
+	}
+	return m.Rule.GroupBy
+}
+
+// bucketLines renders at most n aggregation buckets as "`key` → metric=value（count 条）"
+func bucketLines(buckets []models.AggregationBucket, metric string, n int) []string {
+	lines := make([]string, 0, n+1)
+	for i, b := range buckets {
+		if i == n {
+			lines = append(lines, fmt.Sprintf("… 另有 %d 个桶", len(buckets)-n))
+			break
+		}
+		key := "全部日志"
+		if b.Key != "" {
+			key = "`" + b.Key + "`"
+		}
+		lines = append(lines, fmt.Sprintf("%s → %s=%s（%d 条）", key, metric, models.FormatMetricValue(b.Value), b.DocCount))
+	}
+	return lines
+}
+
+// bucketMetric returns the metric label of the alert rule's aggregation
+func (m *AlertMessage) bucketMetric() string {
+	if m.Rule == nil || m.Rule.Aggregation == nil {
+		return "value"
+	}
+	return m.Rule.Aggregation.MetricLabel()
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// DedupKey returns the stable incident key of a rule, so repeated matches of the
+// same rule update one open incident instead of creating a new one each interval
+func DedupKey(rule *models.Rule) string {
+	if rule == nil {
+		return "elk-helper-rule-0"
+	}
+	return fmt.Sprintf("elk-helper-rule-%d", rule.ID)
+}
+
+// incidentSummary is the one-line title used by incident management tools
+func incidentSummary(msg *AlertMessage, logCount int) string {
+	if r := msg.rendered(); r != nil {
+		return r.Title
+	}
+	return fmt.Sprintf("[ELK] %s: %d 条日志匹配（%s）", msg.RuleName, logCount, msg.IndexName)
+}
+
+// incidentDetails collects custom details shared by PagerDuty and Opsgenie
+func incidentDetails(msg *AlertMessage, logCount int) map[string]string {
+	details := map[string]string{
+		"rule_name":  msg.RuleName,
+		"index_name": msg.IndexName,
+		"log_count":  fmt.Sprintf("%d", logCount),
+		"time_range": fmt.Sprintf("%s ~ %s", formatTime(msg.FromTime), formatTime(msg.ToTime)),
+	}
+	if msg.AlertID > 0 {
+		details["alert_id"] = fmt.Sprintf("%d", msg.AlertID)
+	}
+	if msg.Rule != nil && msg.Rule.ESConfig != nil {
+		details["es_config"] = msg.Rule.ESConfig.Name
+	}
+	for i, log := range sampleLogs(msg.Logs, 3) {
+		var lines []string
+		for _, f := range msg.logFields(log) {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.Label, f.Value))
+		}
+		details[fmt.Sprintf("sample_%d", i+1)] = truncateUTF8(strings.Join(lines, "\n"), 1024)
+	}
+	return details
+}
+
+// postIncidentJSON sends a JSON request once and reports whether the failure is worth retrying
+func postIncidentJSON(client *http.Client, url string, headers map[string]string, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	default:
+		return false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+}
+
+// sendIncidentWithRetry posts an incident event with retry and backoff
+func sendIncidentWithRetry(client *http.Client, channel, ruleName, url string, headers map[string]string, body []byte, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		retryable, err := postIncidentJSON(client, url, headers, body)
+		if err == nil {
+			slog.Info("Incident event sent successfully", "channel", channel, "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+		slog.Warn("Incident event send failed", "channel", channel, "rule_name", ruleName, "attempt", attempt, "error", err)
+
+		if !retryable {
+			return fmt.Errorf("%s API error: %w", channel, err)
+		}
+		if attempt < retryTimes {
+			time.Sleep(backoffWithJitter(attempt))
+		}
+	}
+
+	slog.Error("Failed to send incident event after all attempts", "channel", channel, "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send to %s after %d attempts: %w", channel, retryTimes, lastErr)
+}
@@ -7,50 +7,80 @@ package notifier
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
 )
 
+// LarkSettings holds the settings of a Lark channel
+type LarkSettings struct {
+	WebhookURL string `json:"webhook_url"`
+	Secret     string `json:"secret,omitempty"` // 签名校验密钥，为空则不签名
+}
+
 // LarkClient handles Lark webhook notifications
 type LarkClient struct {
 	webhookURL string
+	secret     string
 	httpClient *http.Client
 }
 
-// NewLarkClient creates a new Lark client
-func NewLarkClient(webhookURL string) *LarkClient {
+// NewLarkClient creates a new Lark client.
+// secret is the bot's signature verification key; leave it empty if signing is disabled.
+func NewLarkClient(webhookURL, secret string) *LarkClient {
 	return &LarkClient{
 		webhookURL: webhookURL,
+		secret:     secret,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
 }
 
+// Type returns the channel type
+func (lc *LarkClient) Type() string {
+	return models.ChannelTypeLark
+}
+
 // SendAlert sends alert message with logs to Lark
-func (lc *LarkClient) SendAlert(ruleName, indexName string, logs []map[string]interface{}, logCount int, fromTime, toTime time.Time, retryTimes int) error {
+func (lc *LarkClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	ruleName := msg.RuleName
+	logCount := msg.LogCount
 	if logCount <= 0 {
-		logCount = len(logs)
+		logCount = len(msg.Logs)
 	}
 
-	slog.Info("Sending alert to Lark", "rule_name", ruleName, "index_name", indexName, "log_count", logCount, "webhook_url", lc.webhookURL, "retry_times", retryTimes)
-	message := lc.buildMessage(ruleName, indexName, logs, logCount, fromTime, toTime)
+	slog.Info("Sending alert to Lark", "rule_name", ruleName, "index_name", msg.IndexName, "log_count", logCount, "webhook_url", lc.webhookURL, "signed", lc.secret != "", "retry_times", retryTimes)
+	message := lc.buildMessage(msg, logCount)
 
+	return lc.send(msg.Context(), message, ruleName, retryTimes)
+}
+
+// send posts a card message with retry, re-signing on every attempt
+func (lc *LarkClient) send(ctx context.Context, message map[string]interface{}, ruleName string, retryTimes int) error {
 	for attempt := 1; attempt <= retryTimes; attempt++ {
 		slog.Debug("Lark send attempt", "rule_name", ruleName, "attempt", attempt, "max_attempts", retryTimes)
+		// Sign on every attempt: Lark rejects timestamps older than 1 hour
+		lc.sign(message)
 		body, err := json.Marshal(message)
 		if err != nil {
 			slog.Error("Failed to marshal message", "rule_name", ruleName, "error", err)
 			return fmt.Errorf("failed to marshal message: %w", err)
 		}
 
-		req, err := http.NewRequest("POST", lc.webhookURL, bytes.NewReader(body))
+		req, err := http.NewRequestWithContext(ctx, "POST", lc.webhookURL, bytes.NewReader(body))
 		if err != nil {
 			slog.Error("Failed to create request", "rule_name", ruleName, "error", err)
 			return fmt.Errorf("failed to create request: %w", err)
@@ -62,8 +92,9 @@ func (lc *LarkClient) SendAlert(ruleName, indexName string, logs []map[string]in
 		if err != nil {
 			slog.Warn("Lark request failed", "rule_name", ruleName, "attempt", attempt, "error", err)
 			if attempt < retryTimes {
-				waitTime := backoffWithJitter(attempt)
-				time.Sleep(waitTime)
+				if err := waitRetry(ctx, backoffWithJitter(attempt)); err != nil {
+					return fmt.Errorf("lark send aborted after %d attempts: %w", attempt, err)
+				}
 				continue
 			}
 			slog.Error("Failed to send to Lark after all attempts", "rule_name", ruleName, "attempts", retryTimes, "error", err)
@@ -77,8 +108,9 @@ func (lc *LarkClient) SendAlert(ruleName, indexName string, logs []map[string]in
 		if err := json.Unmarshal(respBody, &result); err != nil {
 			slog.Warn("Failed to parse Lark response", "rule_name", ruleName, "attempt", attempt, "error", err, "response_body", string(respBody))
 			if attempt < retryTimes {
-				waitTime := backoffWithJitter(attempt)
-				time.Sleep(waitTime)
+				if err := waitRetry(ctx, backoffWithJitter(attempt)); err != nil {
+					return fmt.Errorf("lark send aborted after %d attempts: %w", attempt, err)
+				}
 				continue
 			}
 			slog.Error("Failed to parse Lark response after all attempts", "rule_name", ruleName, "error", err)
@@ -96,8 +128,9 @@ func (lc *LarkClient) SendAlert(ruleName, indexName string, logs []map[string]in
 		}
 
 		if attempt < retryTimes {
-			waitTime := backoffWithJitter(attempt)
-			time.Sleep(waitTime)
+			if err := waitRetry(ctx, backoffWithJitter(attempt)); err != nil {
+				return fmt.Errorf("lark send aborted after %d attempts: %w", attempt, err)
+			}
 		} else {
 			slog.Error("Lark API error after all attempts", "rule_name", ruleName, "response", result)
 			return fmt.Errorf("lark API error: %v", result)
@@ -108,6 +141,92 @@ func (lc *LarkClient) SendAlert(ruleName, indexName string, logs []map[string]in
 	return fmt.Errorf("failed to send to Lark after %d attempts", retryTimes)
 }
 
+// SendResolve sends a green card telling the alert has recovered
+func (lc *LarkClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved notification to Lark", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+	message := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]interface{}{
+				"wide_screen_mode": true,
+			},
+			"header": map[string]interface{}{
+				"title": map[string]interface{}{
+					"tag":     "plain_text",
+					"content": resolvedTitle,
+				},
+				"template": "green",
+			},
+			"elements": []map[string]interface{}{
+				{
+					"tag": "div",
+					"text": map[string]interface{}{
+						"tag":     "lark_md",
+						"content": renderResolvedMarkdown(msg, "\n"),
+					},
+				},
+			},
+		},
+	}
+	return lc.send(msg.Context(), message, msg.RuleName, retryTimes)
+}
+
+// SendTest sends a plain text test message to Lark
+func (lc *LarkClient) SendTest() error {
+	testMessage := map[string]interface{}{
+		"msg_type": "text",
+		"content": map[string]interface{}{
+			"text": "测试消息：ELK Helper 连接测试",
+		},
+	}
+	lc.sign(testMessage)
+
+	body, err := json.Marshal(testMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", lc.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := lc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse Lark response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		if code, ok := result["code"].(float64); ok && code == 0 {
+			return nil
+		}
+	}
+
+	if msg, ok := result["msg"].(string); ok && msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	return fmt.Errorf("Lark API 返回错误")
+}
+
+// sign adds timestamp and sign fields to the message when a secret is configured.
+// sign = base64(HmacSHA256(key = timestamp + "\n" + secret, data = ""))
+func (lc *LarkClient) sign(message map[string]interface{}) {
+	if lc.secret == "" {
+		return
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+lc.secret))
+	message["timestamp"] = timestamp
+	message["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
 func backoffWithJitter(attempt int) time.Duration {
 	// Exponential backoff with upper bound and small jitter.
 	// attempt starts from 1.
@@ -119,7 +238,13 @@ func backoffWithJitter(attempt int) time.Duration {
 	return base + jitter
 }
 
-func (lc *LarkClient) buildMessage(ruleName, indexName string, logs []map[string]interface{}, logCount int, fromTime, toTime time.Time) map[string]interface{} {
+func (lc *LarkClient) buildMessage(msg *AlertMessage, logCount int) map[string]interface{} {
+	if r := msg.rendered(); r != nil {
+		return larkTemplatedMessage(r)
+	}
+
+	ruleName, indexName, logs := msg.RuleName, msg.IndexName, msg.Logs
+	fromTime, toTime := msg.FromTime, msg.ToTime
 	elements := []map[string]interface{}{
 		{
 			"tag": "div",
@@ -154,10 +279,37 @@ func (lc *LarkClient) buildMessage(ruleName, indexName string, logs []map[string
 				"content": fmt.Sprintf("**📊 索引名称**\n`%s`", indexName),
 			},
 		},
-		{
-			"tag": "hr",
-		},
 	}
+	if msg.Condition != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag": "div",
+			"text": map[string]interface{}{
+				"tag":     "lark_md",
+				"content": fmt.Sprintf("**📐 触发条件**\n%s", msg.Condition),
+			},
+		})
+	}
+	if len(msg.Groups) > 0 {
+		elements = append(elements, map[string]interface{}{
+			"tag": "div",
+			"text": map[string]interface{}{
+				"tag":     "lark_md",
+				"content": fmt.Sprintf("**🧩 告警分组**（%d 个）\n%s", len(msg.Groups), strings.Join(groupLines(msg.Groups, msg.groupFields(), 5), "\n")),
+			},
+		})
+	}
+	if len(msg.Buckets) > 0 {
+		elements = append(elements, map[string]interface{}{
+			"tag": "div",
+			"text": map[string]interface{}{
+				"tag":     "lark_md",
+				"content": fmt.Sprintf("**📈 聚合结果**（%d 个桶满足条件）\n%s", len(msg.Buckets), strings.Join(bucketLines(msg.Buckets, msg.bucketMetric(), 10), "\n")),
+			},
+		})
+	}
+	elements = append(elements, map[string]interface{}{
+		"tag": "hr",
+	})
 
 	// Show summary of logs in card format (max 3 samples)
 	if len(logs) > 0 && logCount > 0 {
@@ -178,7 +330,7 @@ func (lc *LarkClient) buildMessage(ruleName, indexName string, logs []map[string
 		// Build each log entry as a separate card section
 		for i := 0; i < displayCount; i++ {
 			log := logs[i]
-			logFields := lc.extractLogFields(i+1, log, ruleName)
+			logFields := larkLogFields(i+1, msg.logFields(log))
 
 			// Add a separator before each log entry (except the first one)
 			if i > 0 {
@@ -248,227 +400,68 @@ func (lc *LarkClient) buildMessage(ruleName, indexName string, logs []map[string
 	}
 }
 
-// extractLogFields extracts key fields from a log entry and formats as card fields
-// Uses rule name to determine the log type and shows relevant fields:
-// - Rule name contains "nginx": response_code, @timestamp, request, cf_ray, domain
-// - Rule name contains "java", "go", "c++", "python", "nodejs", etc.: module, node_ip, message, @timestamp
-func (lc *LarkClient) extractLogFields(rowNum int, log map[string]interface{}, ruleName string) []map[string]interface{} {
-	// Detect log type from rule name (case insensitive)
-	ruleNameLower := strings.ToLower(ruleName)
-
-	// Check if rule name contains "nginx"
-	if strings.Contains(ruleNameLower, "nginx") {
-		return lc.extractNginxLogFields(rowNum, log)
-	}
-
-	// Check if rule name contains application log types (java, go, c++, python, nodejs, app, etc.)
-	appLogTypes := []string{"java", "go", "c++", "cpp", "python", "nodejs", "node", "app", "application", "service", "api", "web"}
-	for _, appType := range appLogTypes {
-		if strings.Contains(ruleNameLower, appType) {
-			return lc.extractAppLogFields(rowNum, log)
-		}
-	}
-
-	// Fallback: try to detect from log fields
-	if _, hasResponseCode := log["response_code"]; hasResponseCode {
-		return lc.extractNginxLogFields(rowNum, log)
+// larkTemplatedMessage renders a notification template as a card with a single markdown section
+func larkTemplatedMessage(r *RenderedMessage) map[string]interface{} {
+	// Lark card headers only accept named colours
+	color := colorName(r.Color)
+	if color == "gray" {
+		color = "grey"
 	}
-	if _, hasModule := log["module"]; hasModule {
-		if _, hasMessage := log["message"]; hasMessage {
-			return lc.extractAppLogFields(rowNum, log)
-		}
+	return map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]interface{}{
+				"wide_screen_mode": true,
+			},
+			"header": map[string]interface{}{
+				"title": map[string]interface{}{
+					"tag":     "plain_text",
+					"content": r.Title,
+				},
+				"template": color,
+			},
+			"elements": []map[string]interface{}{
+				{
+					"tag": "div",
+					"text": map[string]interface{}{
+						"tag":     "lark_md",
+						"content": r.Body,
+					},
+				},
+			},
+		},
 	}
-
-	// Default fallback to app log format (more generic)
-	return lc.extractAppLogFields(rowNum, log)
 }
 
-// extractNginxLogFields extracts fields for nginx/nginx-access logs
-// Shows: response_code, @timestamp, request, cf_ray, domain
-func (lc *LarkClient) extractNginxLogFields(rowNum int, log map[string]interface{}) []map[string]interface{} {
-	fields := []map[string]interface{}{}
-
-	// 1. Response Code - highlighted
-	responseCode := "-"
-	if val, ok := log["response_code"]; ok && val != nil {
-		responseCode = fmt.Sprintf("%v", val)
-	} else if val, ok := log["status_code"]; ok && val != nil {
-		responseCode = fmt.Sprintf("%v", val)
-	} else if val, ok := log["status"]; ok && val != nil {
-		responseCode = fmt.Sprintf("%v", val)
-	}
-
-	// 2. Timestamp
-	timestamp := lc.formatTimestamp(log)
-
-	// 3. Request URL (without query parameters)
-	requestURL := "-"
-	if val, ok := log["request"]; ok && val != nil && val != "" {
-		requestStr := fmt.Sprintf("%v", val)
-		if idx := strings.Index(requestStr, "?"); idx > 0 {
-			requestStr = requestStr[:idx]
-		}
-		if len(requestStr) > 50 {
-			requestStr = requestStr[:50] + "..."
+// larkLogFields renders extracted log fields as Lark card fields
+func larkLogFields(rowNum int, fields []logField) []map[string]interface{} {
+	cardFields := make([]map[string]interface{}, 0, len(fields))
+	for i, f := range fields {
+		label := f.Label
+		if i == 0 {
+			label = fmt.Sprintf("#%d | %s", rowNum, label)
 		}
-		requestURL = requestStr
-	} else if val, ok := log["path"]; ok && val != nil && val != "" {
-		pathStr := fmt.Sprintf("%v", val)
-		if idx := strings.Index(pathStr, "?"); idx > 0 {
-			pathStr = pathStr[:idx]
-		}
-		if len(pathStr) > 50 {
-			pathStr = pathStr[:50] + "..."
-		}
-		requestURL = pathStr
-	}
-
-	// 4. CF Ray ID
-	cfRay := "-"
-	if val, ok := log["cf_ray"]; ok && val != nil && val != "" {
-		cfRay = fmt.Sprintf("%v", val)
-	}
-
-	// 5. Domain
-	domain := "-"
-	if val, ok := log["domain"]; ok && val != nil && val != "" {
-		domain = fmt.Sprintf("%v", val)
-	}
-
-	// Build fields
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**#%d | 状态码:** <font color='red'>%s</font>", rowNum, responseCode),
-		},
-	})
-
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**⏰ 时间:** %s", timestamp),
-		},
-	})
-
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**🔗 URL:** `%s`", requestURL),
-		},
-	})
-
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**☁️ CF Ray:** `%s`", cfRay),
-		},
-	})
 
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**🌐 Domain:** `%s`", domain),
-		},
-	})
-
-	return fields
-}
-
-// extractAppLogFields extracts fields for application logs (java, go, c++, python, nodejs, etc.)
-// Shows: module, node_ip, message, @timestamp
-func (lc *LarkClient) extractAppLogFields(rowNum int, log map[string]interface{}) []map[string]interface{} {
-	fields := []map[string]interface{}{}
-
-	// 1. Module
-	module := "-"
-	if val, ok := log["module"]; ok && val != nil && val != "" {
-		module = fmt.Sprintf("%v", val)
-	}
-
-	// 2. Node IP
-	nodeIP := "-"
-	if val, ok := log["node_ip"]; ok && val != nil && val != "" {
-		nodeIP = fmt.Sprintf("%v", val)
-	}
-
-	// 3. Message (truncate if too long)
-	message := "-"
-	if val, ok := log["message"]; ok && val != nil && val != "" {
-		messageStr := fmt.Sprintf("%v", val)
-		// Truncate to first 200 chars for display
-		if len(messageStr) > 200 {
-			messageStr = messageStr[:200] + "..."
+		var content string
+		switch f.Style {
+		case styleHighlight:
+			content = fmt.Sprintf("**%s:** <font color='%s'>%s</font>", label, colorName(f.Color), f.Value)
+		case styleCode:
+			content = fmt.Sprintf("**%s:** `%s`", label, f.Value)
+		case styleBlock:
+			content = fmt.Sprintf("**%s:**\n```\n%s\n```", label, f.Value)
+		default:
+			content = fmt.Sprintf("**%s:** %s", label, f.Value)
 		}
-		// Replace newlines with spaces for compact display
-		messageStr = strings.ReplaceAll(messageStr, "\n", " ")
-		messageStr = strings.ReplaceAll(messageStr, "\r", "")
-		message = messageStr
-	}
-
-	// 4. Timestamp
-	timestamp := lc.formatTimestamp(log)
-
-	// Build fields - Application log format
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**#%d | 📦 模块:** `%s`", rowNum, module),
-		},
-	})
-
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**🖥️ 节点:** `%s`", nodeIP),
-		},
-	})
-
-	fields = append(fields, map[string]interface{}{
-		"is_short": true,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**⏰ 时间:** %s", timestamp),
-		},
-	})
-
-	// Message as full-width field (not short)
-	fields = append(fields, map[string]interface{}{
-		"is_short": false,
-		"text": map[string]interface{}{
-			"tag":     "lark_md",
-			"content": fmt.Sprintf("**💬 消息:**\n```\n%s\n```", message),
-		},
-	})
-
-	return fields
-}
 
-// formatTimestamp formats the @timestamp field
-func (lc *LarkClient) formatTimestamp(log map[string]interface{}) string {
-	timestamp := "-"
-	if val, ok := log["@timestamp"]; ok && val != nil {
-		timestampStr := fmt.Sprintf("%v", val)
-		// If it's ISO format, try to format it nicely
-		if strings.Contains(timestampStr, "T") {
-			timestampStr = strings.Replace(timestampStr, "T", " ", 1)
-			timestampStr = strings.Replace(timestampStr, "Z", "", 1)
-			// Truncate milliseconds if present
-			if idx := strings.Index(timestampStr, "."); idx > 0 {
-				timestampStr = timestampStr[:idx]
-			}
-		}
-		timestamp = timestampStr
+		cardFields = append(cardFields, map[string]interface{}{
+			// Code blocks take the full width
+			"is_short": f.Style != styleBlock,
+			"text": map[string]interface{}{
+				"tag":     "lark_md",
+				"content": content,
+			},
+		})
 	}
-	return timestamp
-}
-
-func formatTime(t time.Time) string {
-	return t.Format("2006-01-02 15:04:05")
+	return cardFields
 }
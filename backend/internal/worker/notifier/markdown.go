@@ -0,0 +1,97 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownStyle captures the markdown dialect differences between chat robots
+type markdownStyle struct {
+	LineBreak string                           // separator between lines
+	Highlight func(value, color string) string // renders emphasized values (e.g. status codes)
+}
+
+// renderMarkdown renders the alert summary (same content as the Lark card) as markdown
+func renderMarkdown(msg *AlertMessage, logCount int, style markdownStyle) string {
+	var lines []string
+	lines = append(lines,
+		"## 🚨 ELK 告警",
+		fmt.Sprintf("**📋 规则名称**：%s", msg.RuleName),
+		fmt.Sprintf("**⏰ 时间范围**：%s ~ %s", formatTime(msg.FromTime), formatTime(msg.ToTime)),
+		fmt.Sprintf("**🔔 告警数量**：%d 条", logCount),
+	)
+	if msg.Condition != "" {
+		lines = append(lines, fmt.Sprintf("**📐 触发条件**：%s", msg.Condition))
+	}
+	lines = append(lines, fmt.Sprintf("**📊 索引名称**：`%s`", msg.IndexName))
+	if len(msg.Groups) > 0 {
+		lines = append(lines, fmt.Sprintf("**🧩 告警分组**（%d 个）：", len(msg.Groups)))
+		lines = append(lines, groupLines(msg.Groups, msg.groupFields(), 5)...)
+	}
+	if len(msg.Buckets) > 0 {
+		lines = append(lines, fmt.Sprintf("**📈 聚合结果**（%d 个桶满足条件）：", len(msg.Buckets)))
+		lines = append(lines, bucketLines(msg.Buckets, msg.bucketMetric(), 10)...)
+	}
+
+	// Show summary of logs (max 3 samples)
+	samples := sampleLogs(msg.Logs, 3)
+	if len(samples) > 0 && logCount > 0 {
+		lines = append(lines, "---", fmt.Sprintf("**📝 日志摘要**（共 %d 条，展示前 3 条）", logCount))
+
+		for i, log := range samples {
+			for j, f := range msg.logFields(log) {
+				label := f.Label
+				if j == 0 {
+					label = fmt.Sprintf("#%d | %s", i+1, label)
+				}
+				lines = append(lines, markdownField(label, f, style))
+			}
+			if i < len(samples)-1 {
+				lines = append(lines, "---")
+			}
+		}
+
+		if logCount > 3 {
+			lines = append(lines, fmt.Sprintf("**➕ 还有 %d 条日志未显示**，查看完整日志请登录系统", logCount-3))
+		}
+	}
+
+	lines = append(lines, "---", "💡 完整日志详情请登录 ELK Helper 系统查看")
+	return strings.Join(lines, style.LineBreak)
+}
+
+func markdownField(label string, f logField, style markdownStyle) string {
+	switch f.Style {
+	case styleHighlight:
+		value := f.Value
+		if style.Highlight != nil {
+			value = style.Highlight(value, f.Color)
+		}
+		return fmt.Sprintf("**%s:** %s", label, value)
+	case styleCode:
+		return fmt.Sprintf("**%s:** `%s`", label, f.Value)
+	case styleBlock:
+		// Chat robot markdown has no reliable code block support, use a quote instead
+		return fmt.Sprintf("**%s:**%s> %s", label, style.LineBreak, f.Value)
+	default:
+		return fmt.Sprintf("**%s:** %s", label, f.Value)
+	}
+}
+
+// truncateUTF8 truncates s to at most maxBytes without splitting a UTF-8 character
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	const suffix = "..."
+	cut := maxBytes - len(suffix)
+	for cut > 0 && (s[cut]&0xC0) == 0x80 {
+		cut--
+	}
+	return s[:cut] + suffix
+}
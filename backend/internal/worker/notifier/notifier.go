@@ -0,0 +1,199 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// AlertMessage carries everything a notifier needs to render an alert
+type AlertMessage struct {
+	AlertID   uint // ID of the alert record, 0 if it could not be created
+	Rule      *models.Rule
+	RuleName  string
+	IndexName string
+	Logs      []map[string]interface{} // sampled logs for display
+	LogCount  int                      // total matched logs (may exceed len(Logs))
+	FromTime  time.Time
+	ToTime    time.Time
+	Template  *models.NotificationTemplate // notification template of the target, nil for the built-in layout
+	Groups    []models.AlertGroup          // groups being notified when the rule has group_by fields
+	Condition string                       // condition that triggered the alert, empty for match rules
+	Buckets   []models.AggregationBucket   // offending buckets of aggregation rules, shown instead of log samples
+
+	ctx context.Context // bounds the delivery, set with WithContext
+}
+
+// WithContext returns a copy of the message whose delivery stops waiting between
+// retries once ctx is done
+func (m *AlertMessage) WithContext(ctx context.Context) *AlertMessage {
+	msg := *m
+	msg.ctx = ctx
+	return &msg
+}
+
+// Context returns the context bounding the delivery of the message
+func (m *AlertMessage) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+// Notifier delivers alert messages to a notification channel
+type Notifier interface {
+	// Type returns the channel type (e.g. "lark", "slack")
+	Type() string
+	// SendAlert sends an alert message, retrying up to retryTimes attempts
+	SendAlert(msg *AlertMessage, retryTimes int) error
+	// SendTest sends a simple test message to verify channel connectivity
+	SendTest() error
+}
+
+// Resolver is implemented by notifiers that can tell an alert has resolved:
+// incident tools close the incident, chat channels post a recovery message
+type Resolver interface {
+	// SendResolve notifies that the alert of msg.Rule has resolved
+	SendResolve(msg *AlertMessage, retryTimes int) error
+}
+
+// Target is a notifier bound to the channel it was built from
+type Target struct {
+	Name     string
+	Notifier Notifier
+	Template *models.NotificationTemplate // rule template, else channel template, else nil
+}
+
+// New creates a notifier for the given channel configuration
+func New(channel *models.ChannelConfig) (Notifier, error) {
+	if channel == nil {
+		return nil, fmt.Errorf("channel config is nil")
+	}
+
+	switch channel.Type {
+	case models.ChannelTypeLark:
+		var settings LarkSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid lark settings: %w", err)
+		}
+		if settings.WebhookURL == "" {
+			return nil, fmt.Errorf("lark webhook_url is required")
+		}
+		return NewLarkClient(settings.WebhookURL, settings.Secret), nil
+	case models.ChannelTypeSlack:
+		var settings SlackSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid slack settings: %w", err)
+		}
+		if settings.WebhookURL == "" {
+			return nil, fmt.Errorf("slack webhook_url is required")
+		}
+		return NewSlackClient(settings), nil
+	case models.ChannelTypeDingTalk:
+		var settings DingTalkSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid dingtalk settings: %w", err)
+		}
+		if settings.WebhookURL == "" {
+			return nil, fmt.Errorf("dingtalk webhook_url is required")
+		}
+		return NewDingTalkClient(settings), nil
+	case models.ChannelTypeWeCom:
+		var settings WeComSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid wecom settings: %w", err)
+		}
+		if settings.WebhookURL == "" {
+			return nil, fmt.Errorf("wecom webhook_url is required")
+		}
+		return NewWeComClient(settings), nil
+	case models.ChannelTypeEmail:
+		var settings EmailSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid email settings: %w", err)
+		}
+		if err := settings.Validate(); err != nil {
+			return nil, err
+		}
+		return NewEmailClient(settings), nil
+	case models.ChannelTypeWebhook:
+		var settings WebhookSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid webhook settings: %w", err)
+		}
+		client, err := NewWebhookClient(settings)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case models.ChannelTypeAlertmanager:
+		var settings AlertmanagerSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid alertmanager settings: %w", err)
+		}
+		if settings.URL == "" {
+			return nil, fmt.Errorf("alertmanager url is required")
+		}
+		return NewAlertmanagerClient(settings), nil
+	case models.ChannelTypePagerDuty:
+		var settings PagerDutySettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid pagerduty settings: %w", err)
+		}
+		if settings.RoutingKey == "" {
+			return nil, fmt.Errorf("pagerduty routing_key is required")
+		}
+		return NewPagerDutyClient(settings), nil
+	case models.ChannelTypeOpsgenie:
+		var settings OpsgenieSettings
+		if err := channel.Settings.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid opsgenie settings: %w", err)
+		}
+		if settings.APIKey == "" {
+			return nil, fmt.Errorf("opsgenie api_key is required")
+		}
+		return NewOpsgenieClient(settings), nil
+	default:
+		return nil, fmt.Errorf("unsupported channel type: %s", channel.Type)
+	}
+}
+
+// ForRule resolves the notification targets of a rule from its enabled channels.
+// Lark configs and direct webhooks are migrated to lark channels, so they are not consulted.
+func ForRule(rule *models.Rule) ([]Target, error) {
+	var targets []Target
+	var errs []error
+
+	for i := range rule.Channels {
+		channel := &rule.Channels[i]
+		if !channel.Enabled {
+			continue
+		}
+		n, err := New(channel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel.Name, err))
+			continue
+		}
+		template := rule.Template
+		if template == nil {
+			template = channel.Template
+		}
+		targets = append(targets, Target{Name: channel.Name, Notifier: n, Template: template})
+	}
+
+	if len(targets) > 0 {
+		return targets, nil
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("no usable notification channel: %v", errs)
+	}
+
+	return nil, fmt.Errorf("no notification channel configured for rule: channels=%d, none enabled", len(rule.Channels))
+}
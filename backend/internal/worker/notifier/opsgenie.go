@@ -0,0 +1,137 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+const defaultOpsgenieURL = "https://api.opsgenie.com"
+
+// OpsgenieSettings holds the settings of an Opsgenie API integration
+type OpsgenieSettings struct {
+	APIKey   string   `json:"api_key"`            // API Integration Key
+	URL      string   `json:"url,omitempty"`      // 默认 https://api.opsgenie.com（EU 区为 https://api.eu.opsgenie.com）
+	Priority string   `json:"priority,omitempty"` // P1 ~ P5，默认 P3
+	Tags     []string `json:"tags,omitempty"`
+	Team     string   `json:"team,omitempty"` // 负责团队名称
+}
+
+// OpsgenieClient creates and closes Opsgenie alerts
+type OpsgenieClient struct {
+	settings   OpsgenieSettings
+	httpClient *http.Client
+}
+
+// NewOpsgenieClient creates a new Opsgenie client
+func NewOpsgenieClient(settings OpsgenieSettings) *OpsgenieClient {
+	settings.URL = strings.TrimRight(settings.URL, "/")
+	if settings.URL == "" {
+		settings.URL = defaultOpsgenieURL
+	}
+	settings.Priority = strings.ToUpper(settings.Priority)
+	switch settings.Priority {
+	case "P1", "P2", "P3", "P4", "P5":
+	default:
+		settings.Priority = "P3"
+	}
+	return &OpsgenieClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (oc *OpsgenieClient) Type() string {
+	return models.ChannelTypeOpsgenie
+}
+
+// SendAlert creates an alert aliased by the rule ID; Opsgenie deduplicates open alerts with the same alias
+func (oc *OpsgenieClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert to Opsgenie", "rule_name", msg.RuleName, "alias", DedupKey(msg.Rule), "log_count", logCount, "retry_times", retryTimes)
+
+	message, description := incidentSummary(msg, logCount), renderEmailText(msg, logCount)
+	if r := msg.rendered(); r != nil {
+		message, description = r.Title, r.Body
+	}
+
+	alert := map[string]interface{}{
+		"message":     truncateUTF8(message, 130),
+		"alias":       DedupKey(msg.Rule),
+		"description": truncateUTF8(description, 15000),
+		"priority":    oc.settings.Priority,
+		"source":      "elk-helper",
+		"entity":      msg.IndexName,
+		"details":     incidentDetails(msg, logCount),
+	}
+	if len(oc.settings.Tags) > 0 {
+		alert["tags"] = oc.settings.Tags
+	}
+	if oc.settings.Team != "" {
+		alert["responders"] = []map[string]string{{"type": "team", "name": oc.settings.Team}}
+	}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+	return sendIncidentWithRetry(oc.httpClient, "Opsgenie", msg.RuleName, oc.settings.URL+"/v2/alerts", oc.headers(), body, retryTimes)
+}
+
+// SendResolve closes the alert of the rule
+func (oc *OpsgenieClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Closing Opsgenie alert", "rule_name", msg.RuleName, "alias", DedupKey(msg.Rule))
+	return oc.close(DedupKey(msg.Rule), msg.RuleName, retryTimes)
+}
+
+// SendTest creates and immediately closes a test alert
+func (oc *OpsgenieClient) SendTest() error {
+	alias := fmt.Sprintf("elk-helper-test-%d", time.Now().UnixNano())
+	body, err := json.Marshal(map[string]interface{}{
+		"message":  "测试消息：ELK Helper 连接测试",
+		"alias":    alias,
+		"priority": "P5",
+		"source":   "elk-helper",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+	if _, err := postIncidentJSON(oc.httpClient, oc.settings.URL+"/v2/alerts", oc.headers(), body); err != nil {
+		return err
+	}
+	return oc.close(alias, "test", 1)
+}
+
+func (oc *OpsgenieClient) close(alias, ruleName string, retryTimes int) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"source": "elk-helper",
+		"note":   "规则不再匹配，自动关闭",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal close request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", oc.settings.URL, url.PathEscape(alias))
+	return sendIncidentWithRetry(oc.httpClient, "Opsgenie", ruleName, endpoint, oc.headers(), body, retryTimes)
+}
+
+func (oc *OpsgenieClient) headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + oc.settings.APIKey}
+}
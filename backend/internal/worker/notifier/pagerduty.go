@@ -0,0 +1,126 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+const defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySettings holds the settings of a PagerDuty Events API v2 integration
+type PagerDutySettings struct {
+	RoutingKey  string `json:"routing_key"`            // Integration Key
+	Severity    string `json:"severity,omitempty"`     // critical, error, warning, info；默认 error
+	URL         string `json:"url,omitempty"`          // 默认 https://events.pagerduty.com/v2/enqueue
+	ExternalURL string `json:"external_url,omitempty"` // ELK Helper 访问地址，作为 client_url
+}
+
+// PagerDutyClient triggers and resolves PagerDuty incidents
+type PagerDutyClient struct {
+	settings   PagerDutySettings
+	httpClient *http.Client
+}
+
+// NewPagerDutyClient creates a new PagerDuty client
+func NewPagerDutyClient(settings PagerDutySettings) *PagerDutyClient {
+	if settings.URL == "" {
+		settings.URL = defaultPagerDutyURL
+	}
+	settings.Severity = strings.ToLower(settings.Severity)
+	switch settings.Severity {
+	case "critical", "error", "warning", "info":
+	default:
+		settings.Severity = "error"
+	}
+	return &PagerDutyClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (pc *PagerDutyClient) Type() string {
+	return models.ChannelTypePagerDuty
+}
+
+// SendAlert sends a trigger event deduplicated by the rule ID
+func (pc *PagerDutyClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending trigger event to PagerDuty", "rule_name", msg.RuleName, "dedup_key", DedupKey(msg.Rule), "log_count", logCount, "retry_times", retryTimes)
+
+	payload := map[string]interface{}{
+		"summary":        truncateUTF8(incidentSummary(msg, logCount), 1024),
+		"source":         "elk-helper",
+		"severity":       pc.settings.Severity,
+		"timestamp":      msg.ToTime.UTC().Format(time.RFC3339),
+		"component":      msg.IndexName,
+		"class":          "log-alert",
+		"custom_details": incidentDetails(msg, logCount),
+	}
+	if msg.Rule != nil && msg.Rule.ESConfig != nil {
+		payload["group"] = msg.Rule.ESConfig.Name
+	}
+
+	event := pc.event("trigger", DedupKey(msg.Rule))
+	event["payload"] = payload
+	return pc.send(event, msg.RuleName, retryTimes)
+}
+
+// SendResolve resolves the incident of the rule
+func (pc *PagerDutyClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolve event to PagerDuty", "rule_name", msg.RuleName, "dedup_key", DedupKey(msg.Rule))
+	return pc.send(pc.event("resolve", DedupKey(msg.Rule)), msg.RuleName, retryTimes)
+}
+
+// SendTest triggers and immediately resolves a test incident
+func (pc *PagerDutyClient) SendTest() error {
+	dedupKey := fmt.Sprintf("elk-helper-test-%d", time.Now().UnixNano())
+	event := pc.event("trigger", dedupKey)
+	event["payload"] = map[string]interface{}{
+		"summary":  "测试消息：ELK Helper 连接测试",
+		"source":   "elk-helper",
+		"severity": "info",
+	}
+	if err := pc.send(event, "test", 1); err != nil {
+		return err
+	}
+	return pc.send(pc.event("resolve", dedupKey), "test", 1)
+}
+
+func (pc *PagerDutyClient) event(action, dedupKey string) map[string]interface{} {
+	event := map[string]interface{}{
+		"routing_key":  pc.settings.RoutingKey,
+		"event_action": action,
+		"dedup_key":    dedupKey,
+		"client":       "ELK Helper",
+	}
+	if pc.settings.ExternalURL != "" {
+		event["client_url"] = strings.TrimRight(pc.settings.ExternalURL, "/") + "/alerts"
+	}
+	return event
+}
+
+func (pc *PagerDutyClient) send(event map[string]interface{}, ruleName string, retryTimes int) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return sendIncidentWithRetry(pc.httpClient, "PagerDuty", ruleName, pc.settings.URL, nil, body, retryTimes)
+}
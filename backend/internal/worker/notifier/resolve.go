@@ -0,0 +1,81 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// resolvedTitle is the title of resolved notifications
+const resolvedTitle = "✅ ELK 告警恢复"
+
+// resolvedRows are the label/value rows of a resolved notification.
+// For resolve messages FromTime is when the alert started firing and ToTime when it resolved.
+func resolvedRows(msg *AlertMessage) [][2]string {
+	rows := [][2]string{
+		{"📋 规则名称", msg.RuleName},
+		{"📊 索引名称", msg.IndexName},
+		{"⏰ 告警时间", fmt.Sprintf("%s ~ %s", formatTime(msg.FromTime), formatTime(msg.ToTime))},
+		{"⌛ 持续时长", msg.ToTime.Sub(msg.FromTime).Round(time.Second).String()},
+	}
+	if msg.LogCount > 0 {
+		rows = append(rows, [2]string{"🔔 累计日志", fmt.Sprintf("%d 条", msg.LogCount)})
+	}
+	if msg.AlertID > 0 {
+		rows = append(rows, [2]string{"🆔 告警 ID", fmt.Sprintf("%d", msg.AlertID)})
+	}
+	return rows
+}
+
+// renderResolvedMarkdown renders the resolved rows as "**label**：value" lines
+func renderResolvedMarkdown(msg *AlertMessage, lineBreak string) string {
+	lines := make([]string, 0, 6)
+	for _, row := range resolvedRows(msg) {
+		lines = append(lines, fmt.Sprintf("**%s**：%s", row[0], row[1]))
+	}
+	return strings.Join(lines, lineBreak)
+}
+
+// NewResolvedMessage builds the resolved notification of an alert
+func NewResolvedMessage(rule *models.Rule, alert *models.Alert, resolvedAt time.Time) *AlertMessage {
+	return &AlertMessage{
+		AlertID:   alert.ID,
+		Rule:      rule,
+		RuleName:  rule.Name,
+		IndexName: rule.IndexPattern,
+		LogCount:  alert.LogCount,
+		FromTime:  alert.CreatedAt,
+		ToTime:    resolvedAt,
+	}
+}
+
+// SendResolved sends the resolved notification to every target that supports it
+// and joins the failures
+func SendResolved(targets []Target, msg *AlertMessage, retryTimes int) error {
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		resolver, ok := target.Notifier.(Resolver)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			if err := resolver.SendResolve(msg, retryTimes); err != nil {
+				errs[i] = fmt.Errorf("%s(%s): %w", target.Name, target.Notifier.Type(), err)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// robotResponse is the common response body of DingTalk and WeCom group robots
+type robotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// postRobot posts a payload to a group robot once and checks the errcode in the response
+func postRobot(client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result robotResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("errcode %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// sendRobotWithRetry posts a payload with retry and backoff.
+// urlFn is called on every attempt so signed URLs get a fresh timestamp.
+func sendRobotWithRetry(client *http.Client, channel, ruleName string, urlFn func() (string, error), body []byte, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		url, err := urlFn()
+		if err != nil {
+			return err
+		}
+
+		if lastErr = postRobot(client, url, body); lastErr == nil {
+			slog.Info("Alert sent successfully", "channel", channel, "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		slog.Warn("Robot send failed", "channel", channel, "rule_name", ruleName, "attempt", attempt, "error", lastErr)
+
+		if attempt < retryTimes {
+			time.Sleep(backoffWithJitter(attempt))
+		}
+	}
+
+	slog.Error("Failed to send after all attempts", "channel", channel, "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send to %s after %d attempts: %w", channel, retryTimes, lastErr)
+}
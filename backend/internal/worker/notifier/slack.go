@@ -0,0 +1,375 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// maxRetryAfter caps how long we honour a Slack Retry-After header; the wait is further
+// capped by the time left before the send deadline
+const maxRetryAfter = 30 * time.Second
+
+// SlackSettings holds the settings of a Slack channel
+type SlackSettings struct {
+	WebhookURL string `json:"webhook_url"`
+	Mention    string `json:"mention,omitempty"` // e.g. "<!channel>" or "<!subteam^ID>"
+}
+
+// SlackClient handles Slack incoming webhook notifications
+type SlackClient struct {
+	webhookURL string
+	mention    string
+	httpClient *http.Client
+}
+
+// NewSlackClient creates a new Slack client
+func NewSlackClient(settings SlackSettings) *SlackClient {
+	return &SlackClient{
+		webhookURL: settings.WebhookURL,
+		mention:    settings.Mention,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (sc *SlackClient) Type() string {
+	return models.ChannelTypeSlack
+}
+
+// SendAlert sends alert message with logs to Slack as Block Kit
+func (sc *SlackClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert to Slack", "rule_name", msg.RuleName, "index_name", msg.IndexName, "log_count", logCount, "retry_times", retryTimes)
+	body, err := json.Marshal(sc.buildMessage(msg, logCount))
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return sc.send(msg.Context(), body, msg.RuleName, retryTimes)
+}
+
+// send posts a payload with retry, honouring Retry-After on 429
+func (sc *SlackClient) send(ctx context.Context, body []byte, ruleName string, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		wait, retryable, err := sc.post(ctx, body)
+		if err == nil {
+			slog.Info("Alert sent successfully to Slack", "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+		slog.Warn("Slack send failed", "rule_name", ruleName, "attempt", attempt, "error", err)
+
+		if !retryable {
+			return fmt.Errorf("slack API error: %w", err)
+		}
+		if attempt < retryTimes {
+			if wait <= 0 {
+				wait = backoffWithJitter(attempt)
+			}
+			if err := waitRetry(ctx, wait); err != nil {
+				return fmt.Errorf("slack send aborted after %d attempts: %w (last error: %v)", attempt, err, lastErr)
+			}
+		}
+	}
+
+	slog.Error("Failed to send to Slack after all attempts", "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send to Slack after %d attempts: %w", retryTimes, lastErr)
+}
+
+// SendResolve sends a resolved notification to Slack
+func (sc *SlackClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved notification to Slack", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+
+	var fields []map[string]interface{}
+	for _, row := range resolvedRows(msg) {
+		fields = append(fields, slackText(fmt.Sprintf("*%s*\n%s", row[0], slackEscape(row[1]))))
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"text": fmt.Sprintf("%s：%s", resolvedTitle, msg.RuleName),
+		"blocks": []map[string]interface{}{
+			{
+				"type": "header",
+				"text": map[string]interface{}{
+					"type": "plain_text",
+					"text": resolvedTitle,
+				},
+			},
+			{
+				"type":   "section",
+				"fields": fields,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return sc.send(msg.Context(), body, msg.RuleName, retryTimes)
+}
+
+// SendTest sends a plain text test message to Slack
+func (sc *SlackClient) SendTest() error {
+	body, err := json.Marshal(map[string]interface{}{
+		"text": "测试消息：ELK Helper 连接测试",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	_, _, err = sc.post(context.Background(), body)
+	return err
+}
+
+// post sends a payload once. It returns the server requested wait time (429 Retry-After)
+// and whether the failure is worth retrying.
+func (sc *SlackClient) post(ctx context.Context, body []byte) (time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", sc.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), true, fmt.Errorf("rate limited (429): %s", strings.TrimSpace(string(respBody)))
+	case resp.StatusCode >= 500:
+		return 0, true, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	default:
+		// 4xx such as invalid_payload / no_service will not succeed on retry
+		return 0, false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+// waitRetry sleeps before the next attempt. The wait is capped at the time left before
+// the context deadline, and it returns the context error as soon as ctx is done.
+func waitRetry(ctx context.Context, wait time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline); wait > left {
+			wait = left
+		}
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (sc *SlackClient) buildMessage(msg *AlertMessage, logCount int) map[string]interface{} {
+	if r := msg.rendered(); r != nil {
+		return sc.buildTemplatedMessage(r)
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "🚨 ELK 告警",
+			},
+		},
+		slackSection(fmt.Sprintf("*📋 规则名称*\n%s", slackEscape(msg.RuleName))),
+		{
+			"type": "section",
+			"fields": []map[string]interface{}{
+				slackText(fmt.Sprintf("*⏰ 时间范围*\n%s\n%s", formatTime(msg.FromTime), formatTime(msg.ToTime))),
+				slackText(fmt.Sprintf("*🔔 告警数量*\n%d 条", logCount)),
+			},
+		},
+		slackSection(fmt.Sprintf("*📊 索引名称*\n`%s`", slackEscape(msg.IndexName))),
+	}
+	if msg.Condition != "" {
+		blocks = append(blocks, slackSection(fmt.Sprintf("*📐 触发条件*\n%s", slackEscape(msg.Condition))))
+	}
+	if len(msg.Groups) > 0 {
+		blocks = append(blocks, slackSection(fmt.Sprintf("*🧩 告警分组*（%d 个）\n%s",
+			len(msg.Groups), slackEscape(strings.Join(groupLines(msg.Groups, msg.groupFields(), 5), "\n")))))
+	}
+	if len(msg.Buckets) > 0 {
+		blocks = append(blocks, slackSection(fmt.Sprintf("*📈 聚合结果*（%d 个桶满足条件）\n%s",
+			len(msg.Buckets), slackEscape(strings.Join(bucketLines(msg.Buckets, msg.bucketMetric(), 10), "\n")))))
+	}
+	blocks = append(blocks, map[string]interface{}{"type": "divider"})
+
+	// Show summary of logs (max 3 samples)
+	samples := sampleLogs(msg.Logs, 3)
+	if len(samples) > 0 && logCount > 0 {
+		blocks = append(blocks, slackSection(fmt.Sprintf("*📝 日志摘要*（共 %d 条，展示前 3 条）", logCount)))
+
+		for i, log := range samples {
+			if i > 0 {
+				blocks = append(blocks, map[string]interface{}{"type": "divider"})
+			}
+			blocks = append(blocks, slackLogBlocks(i+1, msg.logFields(log))...)
+		}
+
+		if logCount > 3 {
+			blocks = append(blocks, slackContext(fmt.Sprintf("➕ 还有 %d 条日志未显示，查看完整日志请登录系统", logCount-3)))
+		}
+	}
+
+	blocks = append(blocks, map[string]interface{}{"type": "divider"})
+	blocks = append(blocks, slackContext("💡 完整日志详情请登录 ELK Helper 系统查看"))
+	if sc.mention != "" {
+		blocks = append(blocks, slackSection(sc.mention))
+	}
+
+	return map[string]interface{}{
+		// Fallback text for notifications and clients without Block Kit
+		"text":   fmt.Sprintf("🚨 ELK 告警：%s（%d 条）", msg.RuleName, logCount),
+		"blocks": blocks,
+	}
+}
+
+// buildTemplatedMessage renders a notification template as a header and a markdown section
+func (sc *SlackClient) buildTemplatedMessage(r *RenderedMessage) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": truncateUTF8(r.Title, 150),
+			},
+		},
+	}
+	if r.Body != "" {
+		// Section text is limited to 3000 characters
+		blocks = append(blocks, slackSection(truncateUTF8(r.Body, 3000)))
+	}
+	if sc.mention != "" {
+		blocks = append(blocks, slackSection(sc.mention))
+	}
+	return map[string]interface{}{
+		"text":   r.Title,
+		"blocks": blocks,
+	}
+}
+
+// slackLogBlocks renders one log sample: short fields in a section, code blocks full width
+func slackLogBlocks(rowNum int, fields []logField) []map[string]interface{} {
+	var short []map[string]interface{}
+	var blocks []map[string]interface{}
+
+	for i, f := range fields {
+		label := f.Label
+		if i == 0 {
+			label = fmt.Sprintf("#%d | %s", rowNum, label)
+		}
+		value := slackEscape(f.Value)
+
+		switch f.Style {
+		case styleHighlight:
+			short = append(short, slackText(fmt.Sprintf("*%s:* %s *%s*", label, slackColorEmoji(f.Color), value)))
+		case styleCode:
+			short = append(short, slackText(fmt.Sprintf("*%s:* `%s`", label, value)))
+		case styleBlock:
+			blocks = append(blocks, slackSection(fmt.Sprintf("*%s:*\n```%s```", label, value)))
+		default:
+			short = append(short, slackText(fmt.Sprintf("*%s:* %s", label, value)))
+		}
+	}
+
+	if len(short) > 0 {
+		// Slack allows at most 10 fields per section
+		if len(short) > 10 {
+			short = short[:10]
+		}
+		blocks = append([]map[string]interface{}{{"type": "section", "fields": short}}, blocks...)
+	}
+	return blocks
+}
+
+func slackText(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "mrkdwn",
+		"text": text,
+	}
+}
+
+func slackSection(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": slackText(text),
+	}
+}
+
+func slackContext(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "context",
+		"elements": []map[string]interface{}{slackText(text)},
+	}
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn
+func slackEscape(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return s
+}
+
+// slackColorEmoji approximates a highlight colour with a circle emoji (Slack text has no colours)
+func slackColorEmoji(color string) string {
+	switch colorName(color) {
+	case "orange":
+		return ":large_orange_circle:"
+	case "yellow":
+		return ":large_yellow_circle:"
+	case "green":
+		return ":large_green_circle:"
+	case "blue":
+		return ":large_blue_circle:"
+	case "purple":
+		return ":large_purple_circle:"
+	case "grey", "gray":
+		return ":white_circle:"
+	default:
+		return ":red_circle:"
+	}
+}
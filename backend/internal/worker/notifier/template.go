@@ -0,0 +1,233 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// DefaultTemplate mirrors the built-in card layout; it is the starting point
+// offered to users and the fallback of the preview endpoint
+var DefaultTemplate = models.NotificationTemplate{
+	Name:  "default",
+	Title: "🚨 ELK 告警",
+	Color: "red",
+	Body: `**📋 规则名称**：{{.RuleName}}
+**⏰ 时间范围**：{{formatTime .FromTime}} ~ {{formatTime .ToTime}}
+**🔔 告警数量**：{{.LogCount}} 条
+{{- if .Condition}}
+**📐 触发条件**：{{.Condition}}
+{{- end}}
+**📊 索引名称**：` + "`{{.IndexName}}`" + `
+{{- if .Buckets}}
+**📈 聚合结果**（{{len .Buckets}} 个桶满足条件）：
+{{- range .Buckets}}
+{{if .Key}}` + "`{{.Key}}`" + `{{else}}全部日志{{end}} → {{$.Metric}}={{.Value}}（{{.DocCount}} 条）
+{{- end}}
+{{- end}}
+{{- if .Samples}}
+---
+**📝 日志摘要**（共 {{.LogCount}} 条，展示前 {{len .Samples}} 条）
+{{- range $s := .Samples}}
+{{range $i, $f := $s.Fields}}{{if $i}}
+{{end}}**{{if not $i}}#{{$s.Index}} | {{end}}{{$f.Label}}:** {{$f.Value}}{{end}}
+{{- end}}
+{{- end}}
+---
+💡 完整日志详情请登录 ELK Helper 系统查看`,
+}
+
+// TemplateData is the data available to notification templates
+type TemplateData struct {
+	AlertID   uint
+	RuleID    uint
+	RuleName  string
+	IndexName string
+	FromTime  time.Time
+	ToTime    time.Time
+	LogCount  int
+	Logs      []map[string]interface{} // all sampled logs
+	Samples   []TemplateSample         // first 3 logs with their display fields
+	GroupBy   []string                 // group_by fields of the rule
+	Groups    []models.AlertGroup      // notified groups with their log counts in this execution
+	Condition string                   // condition that triggered the alert, empty for match rules
+	Metric    string                   // metric label of aggregation rules, e.g. p99(upstream_response_time)
+	Buckets   []TemplateBucket         // offending buckets of aggregation rules
+}
+
+// TemplateBucket is an offending aggregation bucket with its formatted metric value
+type TemplateBucket struct {
+	Key      string // empty when the aggregation has no group_by field
+	DocCount int64
+	Value    string
+}
+
+// TemplateSample is a sampled log with the display fields configured for the rule
+type TemplateSample struct {
+	Index  int // 1-based
+	Log    map[string]interface{}
+	Fields []TemplateField
+}
+
+// TemplateField is one rendered display field of a sample
+type TemplateField struct {
+	Label string
+	Value string
+}
+
+// RenderedMessage is the result of rendering a notification template
+type RenderedMessage struct {
+	Title string `json:"title"`
+	Color string `json:"color"`
+	Body  string `json:"body"`
+}
+
+// templateFuncs are helper functions available to notification templates
+var templateFuncs = template.FuncMap{
+	// truncate keeps at most n characters, e.g. {{ field .Log "message" | truncate 100 }}
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); n > 0 && len(runes) > n {
+			return string(runes[:n]) + "..."
+		}
+		return s
+	},
+	// formatTimestamp formats an ISO timestamp as "2006-01-02 15:04:05"
+	"formatTimestamp": func(v interface{}) string {
+		return formatTimestampValue(fmt.Sprintf("%v", v))
+	},
+	"formatTime": formatTime,
+	// field looks up a log field by path, "|" separated alternatives allowed; "-" if missing
+	"field": func(log map[string]interface{}, path string) string {
+		raw, ok := lookupFirstField(log, path)
+		if !ok {
+			return "-"
+		}
+		return formatDisplayValue(raw, models.DisplayField{})
+	},
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ValidateTemplate checks the title/body syntax and the header colour of a template
+func ValidateTemplate(t *models.NotificationTemplate) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(t.Title) == "" && strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("title or body is required")
+	}
+	if _, err := template.New("title").Funcs(templateFuncs).Parse(t.Title); err != nil {
+		return fmt.Errorf("invalid title template: %w", err)
+	}
+	if _, err := template.New("body").Funcs(templateFuncs).Parse(t.Body); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	if t.Color != "" && !strings.HasPrefix(t.Color, "#") {
+		if _, ok := highlightColors[strings.ToLower(t.Color)]; !ok {
+			return fmt.Errorf("invalid color: %s", t.Color)
+		}
+	}
+	return nil
+}
+
+// NewTemplateData builds the template data of an alert message
+func NewTemplateData(msg *AlertMessage) *TemplateData {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	data := &TemplateData{
+		AlertID:   msg.AlertID,
+		RuleName:  msg.RuleName,
+		IndexName: msg.IndexName,
+		FromTime:  msg.FromTime,
+		ToTime:    msg.ToTime,
+		LogCount:  logCount,
+		Logs:      msg.Logs,
+	}
+	if msg.Rule != nil {
+		data.RuleID = msg.Rule.ID
+		data.GroupBy = msg.Rule.GroupBy
+	}
+	data.Groups = msg.Groups
+	data.Condition = msg.Condition
+	if len(msg.Buckets) > 0 {
+		data.Metric = msg.bucketMetric()
+		for _, b := range msg.Buckets {
+			data.Buckets = append(data.Buckets, TemplateBucket{Key: b.Key, DocCount: b.DocCount, Value: models.FormatMetricValue(b.Value)})
+		}
+	}
+	for i, log := range sampleLogs(msg.Logs, 3) {
+		sample := TemplateSample{Index: i + 1, Log: log}
+		for _, f := range msg.logFields(log) {
+			sample.Fields = append(sample.Fields, TemplateField{Label: f.Label, Value: f.Value})
+		}
+		data.Samples = append(data.Samples, sample)
+	}
+	return data
+}
+
+// RenderTemplate renders a notification template against the alert data
+func RenderTemplate(t *models.NotificationTemplate, data *TemplateData) (*RenderedMessage, error) {
+	title, err := executeTemplate("title", t.Title, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render title: %w", err)
+	}
+
+	body, err := executeTemplate("body", t.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = DefaultTemplate.Title
+	}
+	color := t.Color
+	if color == "" {
+		color = DefaultTemplate.Color
+	}
+	return &RenderedMessage{Title: title, Color: color, Body: strings.TrimSpace(body)}, nil
+}
+
+func executeTemplate(name, text string, data *TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// rendered renders msg.Template. It returns nil when no template is assigned or
+// rendering fails, in which case notifiers fall back to the built-in layout.
+func (m *AlertMessage) rendered() *RenderedMessage {
+	if m.Template == nil {
+		return nil
+	}
+	r, err := RenderTemplate(m.Template, NewTemplateData(m))
+	if err != nil {
+		slog.Warn("Failed to render notification template, using built-in layout", "rule_name", m.RuleName, "template", m.Template.Name, "error", err)
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+const (
+	defaultSignatureHeader = "X-ELK-Helper-Signature"
+	timestampHeader        = "X-ELK-Helper-Timestamp"
+)
+
+// WebhookSettings holds the settings of a generic outbound webhook
+type WebhookSettings struct {
+	URL             string            `json:"url"`
+	Method          string            `json:"method,omitempty"`           // 默认 POST
+	Headers         map[string]string `json:"headers,omitempty"`          // 自定义请求头（可含 token 等敏感信息）
+	ContentType     string            `json:"content_type,omitempty"`     // 默认 application/json
+	BodyTemplate    string            `json:"body_template,omitempty"`    // Go text/template，为空使用默认 JSON
+	SignatureSecret string            `json:"signature_secret,omitempty"` // 配置后对请求体做 HMAC-SHA256 签名
+	SignatureHeader string            `json:"signature_header,omitempty"` // 签名请求头，默认 X-ELK-Helper-Signature
+}
+
+// WebhookRule is the view of a rule available to body templates. It carries only
+// descriptive fields, so templates cannot reach channel settings or other secrets.
+type WebhookRule struct {
+	ID           uint              `json:"id"`
+	Name         string            `json:"name"`
+	IndexPattern string            `json:"index_pattern"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Description  string            `json:"description,omitempty"`
+}
+
+// newWebhookRule returns the template view of a rule, or nil when there is no rule
+func newWebhookRule(rule *models.Rule) *WebhookRule {
+	if rule == nil {
+		return nil
+	}
+	view := &WebhookRule{
+		ID:           rule.ID,
+		Name:         rule.Name,
+		IndexPattern: rule.IndexPattern,
+		Description:  rule.Description,
+	}
+	if len(rule.Labels) > 0 {
+		view.Labels = make(map[string]string, len(rule.Labels))
+		for k, v := range rule.Labels {
+			view.Labels[k] = v
+		}
+	}
+	return view
+}
+
+// WebhookTemplateData is the data available to body templates
+type WebhookTemplateData struct {
+	AlertID   uint
+	Rule      *WebhookRule // nil for test messages
+	RuleID    uint
+	RuleName  string
+	IndexName string
+	FromTime  time.Time
+	ToTime    time.Time
+	LogCount  int
+	Logs      []map[string]interface{}
+	Condition string                     // condition that triggered the alert, empty for match rules
+	Buckets   []models.AggregationBucket // offending buckets of aggregation rules
+	Title     string                     // rendered notification template title, empty without a template
+	Body      string                     // rendered notification template body, empty without a template
+	Status    string                     // firing or resolved
+	Test      bool                       // true when rendered by the channel test endpoint
+}
+
+// webhookTemplateFuncs are helper functions available to body templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json renders any value as a JSON literal, e.g. {{ json .RuleName }}
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"formatTime": formatTime,
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+// WebhookClient handles generic outbound webhook notifications
+type WebhookClient struct {
+	settings   WebhookSettings
+	tmpl       *template.Template
+	httpClient *http.Client
+}
+
+// NewWebhookClient creates a new webhook client, parsing the body template
+func NewWebhookClient(settings WebhookSettings) (*WebhookClient, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+	settings.Method = strings.ToUpper(strings.TrimSpace(settings.Method))
+	if settings.Method == "" {
+		settings.Method = http.MethodPost
+	}
+	switch settings.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("unsupported webhook method: %s", settings.Method)
+	}
+	if settings.ContentType == "" {
+		settings.ContentType = "application/json"
+	}
+	if settings.SignatureHeader == "" {
+		settings.SignatureHeader = defaultSignatureHeader
+	}
+
+	wc := &WebhookClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+	if settings.BodyTemplate != "" {
+		tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(settings.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body_template: %w", err)
+		}
+		wc.tmpl = tmpl
+	}
+	return wc, nil
+}
+
+// Type returns the channel type
+func (wc *WebhookClient) Type() string {
+	return models.ChannelTypeWebhook
+}
+
+// SendAlert renders the body template and sends it to the webhook
+func (wc *WebhookClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	data := WebhookTemplateData{
+		AlertID:   msg.AlertID,
+		Rule:      newWebhookRule(msg.Rule),
+		RuleName:  msg.RuleName,
+		IndexName: msg.IndexName,
+		FromTime:  msg.FromTime,
+		ToTime:    msg.ToTime,
+		LogCount:  logCount,
+		Logs:      msg.Logs,
+		Condition: msg.Condition,
+		Buckets:   msg.Buckets,
+		Status:    "firing",
+	}
+	if msg.Rule != nil {
+		data.RuleID = msg.Rule.ID
+	}
+	if r := msg.rendered(); r != nil {
+		data.Title, data.Body = r.Title, r.Body
+	}
+
+	slog.Info("Sending alert to webhook", "rule_name", msg.RuleName, "method", wc.settings.Method, "log_count", logCount, "signed", wc.settings.SignatureSecret != "", "retry_times", retryTimes)
+	body, err := wc.render(&data)
+	if err != nil {
+		return err
+	}
+
+	return wc.send(msg.Context(), body, msg.RuleName, retryTimes)
+}
+
+// send posts a rendered body with retry, honouring Retry-After on 429
+func (wc *WebhookClient) send(ctx context.Context, body []byte, ruleName string, retryTimes int) error {
+	var lastErr error
+	for attempt := 1; attempt <= retryTimes; attempt++ {
+		wait, retryable, err := wc.do(ctx, body)
+		if err == nil {
+			slog.Info("Alert sent successfully to webhook", "rule_name", ruleName, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+		slog.Warn("Webhook send failed", "rule_name", ruleName, "attempt", attempt, "error", err)
+
+		if !retryable {
+			return fmt.Errorf("webhook error: %w", err)
+		}
+		if attempt < retryTimes {
+			if wait <= 0 {
+				wait = backoffWithJitter(attempt)
+			}
+			if err := waitRetry(ctx, wait); err != nil {
+				return fmt.Errorf("webhook send aborted after %d attempts: %w (last error: %v)", attempt, err, lastErr)
+			}
+		}
+	}
+
+	slog.Error("Failed to send to webhook after all attempts", "rule_name", ruleName, "attempts", retryTimes, "error", lastErr)
+	return fmt.Errorf("failed to send to webhook after %d attempts: %w", retryTimes, lastErr)
+}
+
+// SendResolve renders the body template with Status "resolved" and sends it
+func (wc *WebhookClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	data := WebhookTemplateData{
+		AlertID:   msg.AlertID,
+		Rule:      newWebhookRule(msg.Rule),
+		RuleName:  msg.RuleName,
+		IndexName: msg.IndexName,
+		FromTime:  msg.FromTime,
+		ToTime:    msg.ToTime,
+		LogCount:  msg.LogCount,
+		Logs:      []map[string]interface{}{},
+		Status:    "resolved",
+	}
+	if msg.Rule != nil {
+		data.RuleID = msg.Rule.ID
+	}
+
+	slog.Info("Sending resolved notification to webhook", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+	body, err := wc.render(&data)
+	if err != nil {
+		return err
+	}
+	return wc.send(msg.Context(), body, msg.RuleName, retryTimes)
+}
+
+// SendTest renders the template with sample data and sends it once
+func (wc *WebhookClient) SendTest() error {
+	now := time.Now()
+	body, err := wc.render(&WebhookTemplateData{
+		RuleName:  "ELK Helper 连接测试",
+		IndexName: "test-*",
+		FromTime:  now.Add(-time.Minute),
+		ToTime:    now,
+		Logs:      []map[string]interface{}{},
+		Status:    "firing",
+		Test:      true,
+	})
+	if err != nil {
+		return err
+	}
+	_, _, err = wc.do(context.Background(), body)
+	return err
+}
+
+// render executes the body template, or builds the default JSON payload
+func (wc *WebhookClient) render(data *WebhookTemplateData) ([]byte, error) {
+	if wc.tmpl == nil {
+		payload := map[string]interface{}{
+			"alert_id":   data.AlertID,
+			"rule_id":    data.RuleID,
+			"rule_name":  data.RuleName,
+			"index_name": data.IndexName,
+			"from_time":  data.FromTime.UTC().Format(time.RFC3339),
+			"to_time":    data.ToTime.UTC().Format(time.RFC3339),
+			"log_count":  data.LogCount,
+			"logs":       data.Logs,
+			"status":     data.Status,
+			"test":       data.Test,
+		}
+		if data.Condition != "" {
+			payload["condition"] = data.Condition
+		}
+		if len(data.Buckets) > 0 {
+			payload["buckets"] = data.Buckets
+		}
+		if data.Title != "" {
+			payload["title"] = data.Title
+			payload["body"] = data.Body
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := wc.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render body_template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// do sends a payload once. It returns the server requested wait time (429 Retry-After)
+// and whether the failure is worth retrying.
+func (wc *WebhookClient) do(ctx context.Context, body []byte) (time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, wc.settings.Method, wc.settings.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", wc.settings.ContentType)
+	req.Header.Set("User-Agent", "elk-helper")
+	for k, v := range wc.settings.Headers {
+		req.Header.Set(k, v)
+	}
+	if wc.settings.SignatureSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(timestampHeader, timestamp)
+		req.Header.Set(wc.settings.SignatureHeader, "sha256="+webhookSignature(wc.settings.SignatureSecret, timestamp, body))
+	}
+
+	resp, err := wc.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), true, fmt.Errorf("rate limited (429): %s", strings.TrimSpace(string(respBody)))
+	case resp.StatusCode >= 500:
+		return 0, true, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	default:
+		return 0, false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+}
+
+// webhookSignature computes hex(HmacSHA256(timestamp + "." + body, secret)).
+// Receivers recompute it from the X-ELK-Helper-Timestamp header and the raw body.
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
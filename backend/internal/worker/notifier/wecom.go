@@ -0,0 +1,141 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kk/elk-helper/backend/internal/models"
+)
+
+// wecomMarkdownLimit is the max markdown content size (bytes) accepted by WeCom robots
+const wecomMarkdownLimit = 4096
+
+// WeComSettings holds the settings of a WeCom (企业微信) group robot
+type WeComSettings struct {
+	WebhookURL          string   `json:"webhook_url"`
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"` // 额外发送 text 消息 @ 的手机号，"@all" 表示所有人
+}
+
+// WeComClient handles WeCom group robot notifications
+type WeComClient struct {
+	settings   WeComSettings
+	httpClient *http.Client
+}
+
+// NewWeComClient creates a new WeCom client
+func NewWeComClient(settings WeComSettings) *WeComClient {
+	return &WeComClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Type returns the channel type
+func (wc *WeComClient) Type() string {
+	return models.ChannelTypeWeCom
+}
+
+// SendAlert sends alert message with logs to WeCom as markdown
+func (wc *WeComClient) SendAlert(msg *AlertMessage, retryTimes int) error {
+	logCount := msg.LogCount
+	if logCount <= 0 {
+		logCount = len(msg.Logs)
+	}
+
+	slog.Info("Sending alert to WeCom", "rule_name", msg.RuleName, "index_name", msg.IndexName, "log_count", logCount, "retry_times", retryTimes)
+
+	var content string
+	if r := msg.rendered(); r != nil {
+		content = "## " + r.Title + "\n" + r.Body
+	} else {
+		content = renderMarkdown(msg, logCount, markdownStyle{
+			LineBreak: "\n",
+			Highlight: func(v, color string) string { return fmt.Sprintf(`<font color="%s">%s</font>`, wecomColor(color), v) },
+		})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"content": truncateUTF8(content, wecomMarkdownLimit),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	webhookURL := func() (string, error) { return wc.settings.WebhookURL, nil }
+	if err := sendRobotWithRetry(wc.httpClient, "WeCom", msg.RuleName, webhookURL, body, retryTimes); err != nil {
+		return err
+	}
+
+	// markdown messages cannot @ members, follow up with a text message when configured
+	if len(wc.settings.MentionedMobileList) > 0 {
+		mention, err := json.Marshal(map[string]interface{}{
+			"msgtype": "text",
+			"text": map[string]interface{}{
+				"content":               fmt.Sprintf("🚨 ELK 告警：%s", msg.RuleName),
+				"mentioned_mobile_list": wc.settings.MentionedMobileList,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal mention message: %w", err)
+		}
+		if err := postRobot(wc.httpClient, wc.settings.WebhookURL, mention); err != nil {
+			slog.Warn("WeCom mention message failed", "rule_name", msg.RuleName, "error", err)
+		}
+	}
+	return nil
+}
+
+// SendResolve sends a resolved notification to WeCom as markdown
+func (wc *WeComClient) SendResolve(msg *AlertMessage, retryTimes int) error {
+	slog.Info("Sending resolved notification to WeCom", "rule_name", msg.RuleName, "alert_id", msg.AlertID)
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"content": `## <font color="info">` + resolvedTitle + "</font>\n" + renderResolvedMarkdown(msg, "\n"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	webhookURL := func() (string, error) { return wc.settings.WebhookURL, nil }
+	return sendRobotWithRetry(wc.httpClient, "WeCom", msg.RuleName, webhookURL, body, retryTimes)
+}
+
+// SendTest sends a plain text test message to WeCom
+func (wc *WeComClient) SendTest() error {
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content": "测试消息：ELK Helper 连接测试",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return postRobot(wc.httpClient, wc.settings.WebhookURL, body)
+}
+
+// wecomColor maps a highlight colour to the three colours supported by WeCom markdown
+func wecomColor(color string) string {
+	switch colorName(color) {
+	case "green", "blue":
+		return "info"
+	case "grey", "gray":
+		return "comment"
+	default:
+		return "warning"
+	}
+}
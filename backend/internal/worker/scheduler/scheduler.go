@@ -19,6 +19,7 @@ import (
 	"github.com/kk/elk-helper/backend/internal/service/rule"
 	system_config "github.com/kk/elk-helper/backend/internal/service/systemconfig"
 	"github.com/kk/elk-helper/backend/internal/worker/executor"
+	"github.com/kk/elk-helper/backend/internal/worker/notifier"
 )
 
 // Scheduler manages rule execution schedule
@@ -289,6 +290,11 @@ func (s *Scheduler) runRule(ctx context.Context, ruleModel models.Rule) {
 				slog.Info("Rule interval updated", "rule_id", ruleID, "rule_name", rule.Name, "interval", interval)
 			}
 
+			// Active hours and maintenance windows are checked before every execution
+			if s.pausedBySchedule(rule) {
+				continue
+			}
+
 			// Execute with latest configuration
 			s.executeRule(ctx, rule)
 		}
@@ -307,45 +313,51 @@ func (s *Scheduler) executeRuleWithReload(ctx context.Context, ruleID uint) {
 	}
 
 	slog.Info("Rule reloaded", "rule_id", ruleID, "rule_name", rule.Name,
-		"lark_config_id", rule.LarkConfigID,
-		"lark_config_loaded", rule.LarkConfig != nil,
+		"channels", len(rule.Channels),
 		"es_config_id", rule.ESConfigID,
 		"es_config_loaded", rule.ESConfig != nil)
 
 	// Validate rule configuration before executing
-	if rule.LarkConfigID != nil && (rule.LarkConfig == nil || !rule.LarkConfig.Enabled) {
-		slog.Warn("Rule has LarkConfigID but config is not loaded or disabled",
+	if _, err := notifier.ForRule(rule); err != nil {
+		slog.Error("Rule has no notification channel configured, skipping execution",
 			"rule_id", ruleID,
 			"rule_name", rule.Name,
-			"lark_config_id", *rule.LarkConfigID,
-			"lark_config_loaded", rule.LarkConfig != nil,
-			"lark_config_enabled", rule.LarkConfig != nil && rule.LarkConfig.Enabled)
+			"channels", len(rule.Channels),
+			"error", err)
+		return
 	}
 
-	webhookURL := rule.LarkWebhook
-	if rule.LarkConfigID != nil && rule.LarkConfig != nil && rule.LarkConfig.Enabled {
-		webhookURL = rule.LarkConfig.WebhookURL
+	if s.pausedBySchedule(rule) {
+		return
 	}
 
-	larkConfigWebhookURL := ""
-	if rule.LarkConfig != nil {
-		larkConfigWebhookURL = rule.LarkConfig.WebhookURL
-	}
+	slog.Info("Rule configuration validated, force executing on startup", "rule_id", ruleID, "rule_name", rule.Name)
+	s.executeRuleForce(ctx, rule)
+}
 
-	if webhookURL == "" {
-		slog.Error("Rule has no webhook URL configured, skipping execution",
-			"rule_id", ruleID,
-			"rule_name", rule.Name,
-			"lark_webhook", rule.LarkWebhook,
-			"lark_config_id", rule.LarkConfigID,
-			"lark_config_loaded", rule.LarkConfig != nil,
-			"lark_config_enabled", rule.LarkConfig != nil && rule.LarkConfig.Enabled,
-			"lark_config_webhook_url", larkConfigWebhookURL)
-		return
+// pausedBySchedule reports whether the rule's schedules keep it from running now.
+// The paused period is skipped by advancing the last run time, so logs from inside
+// a maintenance window (or outside active hours) do not alert once the rule resumes.
+func (s *Scheduler) pausedBySchedule(rule *models.Rule) bool {
+	now := time.Now()
+	allowed, inside := rule.ScheduleAllows(now)
+	if allowed {
+		return false
 	}
 
-	slog.Info("Rule configuration validated, force executing on startup", "rule_id", ruleID, "rule_name", rule.Name, "webhook_url", webhookURL)
-	s.executeRuleForce(ctx, rule)
+	windows := make([]string, 0, len(inside))
+	for _, sc := range inside {
+		windows = append(windows, sc.Name)
+	}
+	slog.Info("Rule paused by schedule, skipping execution", "rule_id", rule.ID, "rule_name", rule.Name, "inside_windows", windows)
+	if err := s.ruleService.UpdateLastRunTime(rule.ID, &now); err != nil {
+		slog.Warn("Failed to update last run time", "rule_id", rule.ID, "error", err)
+	}
+	windowEnd := rule.WindowEnd(now)
+	if err := s.ruleService.UpdateLastWindowEnd(rule.ID, &windowEnd); err != nil {
+		slog.Warn("Failed to update last window end", "rule_id", rule.ID, "error", err)
+	}
+	return true
 }
 
 // executeRule executes a single rule execution in a separate goroutine (with time interval check)
@@ -383,8 +395,6 @@ func (s *Scheduler) executeRuleWithOptions(ctx context.Context, ruleModel *model
 			"rule_name", ruleModel.Name,
 			"error", err,
 			"force_execute", forceExecute,
-			"lark_config_id", ruleModel.LarkConfigID,
-			"lark_config_loaded", ruleModel.LarkConfig != nil,
 			"es_config_id", ruleModel.ESConfigID,
 			"es_config_loaded", ruleModel.ESConfig != nil)
 	} else {